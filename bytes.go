@@ -0,0 +1,108 @@
+package jsonport
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// An Encoding is the encoding of binary data in a STRING, see Json.Bytes.
+type Encoding int
+
+const (
+	// Base64 is the standard base64 encoding with padding, see base64.StdEncoding.
+	Base64 Encoding = iota
+	// Base64URL is the URL-safe base64 encoding with padding, see base64.URLEncoding.
+	Base64URL
+	// RawBase64 is Base64 without padding, see base64.RawStdEncoding.
+	RawBase64
+	// RawBase64URL is Base64URL without padding, see base64.RawURLEncoding.
+	RawBase64URL
+	// Hex is the hexadecimal encoding of both cases, see hex.Decode.
+	Hex
+)
+
+func (e Encoding) String() string {
+	switch e {
+	case Base64:
+		return "Base64"
+	case Base64URL:
+		return "Base64URL"
+	case RawBase64:
+		return "RawBase64"
+	case RawBase64URL:
+		return "RawBase64URL"
+	case Hex:
+		return "Hex"
+	}
+	return "UNKNOWN"
+}
+
+func (e Encoding) base64() *base64.Encoding {
+	switch e {
+	case Base64URL:
+		return base64.URLEncoding
+	case RawBase64:
+		return base64.RawStdEncoding
+	case RawBase64URL:
+		return base64.RawURLEncoding
+	}
+	return base64.StdEncoding
+}
+
+// Bytes decodes current json value of STRING in enc, and appends the bytes to dst.
+// The extended buffer is returned, pass dst[:0] to reuse the buffer of dst.
+// The bytes in the input are decoded directly, without unquoting
+// to a string first unless there are escapes like `\/` in it.
+// The errors of decoding are base64.CorruptInputError and the errors of hex.Decode.
+func (j Json) Bytes(enc Encoding, dst []byte) ([]byte, error) {
+	if j.zero() {
+		return dst, nil
+	}
+	if j.tp != STRING {
+		return dst, j.mismatch(STRING)
+	}
+	s := j.str()
+	if bytes.IndexByte(s, '\\') >= 0 {
+		u, err := j.sp.unquote(s, j.json5)
+		if err != nil {
+			return dst, err
+		}
+		s = []byte(u)
+	}
+	var n int
+	if enc == Hex {
+		n = hex.DecodedLen(len(s))
+	} else {
+		n = enc.base64().DecodedLen(len(s))
+	}
+	l := len(dst)
+	if cap(dst)-l < n {
+		b := make([]byte, l, l+n)
+		copy(b, dst)
+		dst = b
+	}
+	buf := dst[l : l+n]
+	var err error
+	if enc == Hex {
+		n, err = hex.Decode(buf, s)
+	} else {
+		n, err = enc.base64().Decode(buf, s)
+	}
+	if err != nil {
+		return dst[:l], err
+	}
+	return dst[:l+n], nil
+}
+
+// GetBytes decodes json value specified by keys in enc, and appends the bytes to dst,
+// it is equal to Get(keys...).Bytes(enc, dst), but errors of decoding have the path too,
+// like `images[0].data: illegal base64 data at input byte 4`.
+func (j Json) GetBytes(enc Encoding, dst []byte, keys ...interface{}) ([]byte, error) {
+	v := j.Get(keys...)
+	ret, err := v.Bytes(enc, dst)
+	if err != nil && v.err == nil {
+		err = atPath(err, keys)
+	}
+	return ret, err
+}
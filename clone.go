@@ -0,0 +1,62 @@
+package jsonport
+
+// Clone returns a deep copy of j which shares no memory with the input.
+//
+// Json values, like the strings and numbers returned by them,
+// refer to the input passed to Unmarshal without copying for performance.
+// Clone detaches a Json from the input, so the input can be modified or reused
+// after it. Strings already returned by j still refer to the input.
+// Use Options.Copy to copy the input before parsing instead.
+func (j Json) Clone() Json {
+	var c cloner
+	if len(j.b) != 0 {
+		c.src = j.b
+		c.dst = append([]byte(nil), j.b...)
+	}
+	return c.clone(j)
+}
+
+// cloner copies Json values, slices of src are copied as slices of dst.
+type cloner struct {
+	src []byte
+	dst []byte
+}
+
+func (c *cloner) bytes(b []byte) []byte {
+	if len(b) == 0 {
+		return nil
+	}
+	if off := offsetIn(b, c.src); off >= 0 {
+		return c.dst[off : off+len(b)]
+	}
+	return append([]byte(nil), b...)
+}
+
+func (c *cloner) clone(j Json) Json {
+	j.b = c.bytes(j.b)
+	if l := j.lz; l != nil {
+		// the copy is parsed again if needed, errors are located in the copy.
+		data := c.dst
+		if offsetIn(j.b, data) < 0 {
+			data = j.b
+		}
+		j.lz = &lazy{opt: l.opt, data: data, raw: j.b, depth: l.depth}
+		return j
+	}
+	if j.m != nil {
+		m := make([]kv, len(j.m))
+		for i := range j.m {
+			m[i] = kv{k: c.bytes(j.m[i].k), v: c.clone(j.m[i].v)}
+		}
+		j.m = m
+		j.x = newMemberIndex(m)
+	}
+	if j.a != nil {
+		a := make([]Json, len(j.a))
+		for i := range j.a {
+			a[i] = c.clone(j.a[i])
+		}
+		j.a = a
+	}
+	return j
+}
@@ -0,0 +1,59 @@
+package jsonport
+
+// A Coercion is a set of conversions between types allowed by the accessors
+// of Json, like Int of a STRING. Values got by Get, Member, Element and
+// the like inherit the Coercion of the Json they are got from,
+// and so do the elements converted by IntArray and the like.
+type Coercion uint8
+
+const (
+	// StringToNumber converts STRING like "123" by Int, Float and the like,
+	// see Json.StringAsNumber.
+	StringToNumber Coercion = 1 << iota
+	// AnyToBool converts all types by Bool, see Json.AllAsBool.
+	AnyToBool
+	// FractionToInt truncates numbers like 1.5 by Uint, Int32 and the like,
+	// see Json.TruncateFraction.
+	FractionToInt
+	// NumberToString converts NUMBER to its literal like "1.50" by String.
+	NumberToString
+	// StringToBool converts STRING "true" and "false" by Bool.
+	StringToBool
+	// NullToZero converts NULL and missing values to the zero value,
+	// like 0 by Int, "" by String and an empty array by Array and IntArray.
+	NullToZero
+	// SingleToArray converts a value other than ARRAY and NULL
+	// to an ARRAY of the value by Array and IntArray and the like.
+	SingleToArray
+	// EmptyStringToNull converts STRING "" to NULL, by IsNull and by conversions
+	// to types other than STRING.
+	EmptyStringToNull
+)
+
+// SetCoercion sets the Coercion of j, it replaces all coercions enabled before.
+func (j *Json) SetCoercion(c Coercion) {
+	j.co = c
+}
+
+// Coerce returns j with the Coercion c, for a conversion with it only:
+//
+//	n, err := j.Coerce(StringToNumber | NullToZero).GetInt("id")
+func (j Json) Coerce(c Coercion) Json {
+	j.co = c
+	return j
+}
+
+// Coercion returns the Coercion of j.
+func (j Json) Coercion() Coercion {
+	return j.co
+}
+
+// null reports whether j is NULL, or an empty STRING with EmptyStringToNull.
+func (j Json) null() bool {
+	return j.tp == NULL || j.tp == STRING && len(j.b) == 2 && j.co&EmptyStringToNull != 0
+}
+
+// zero reports whether j is converted to the zero value with NullToZero.
+func (j Json) zero() bool {
+	return j.co&NullToZero != 0 && j.null()
+}
@@ -0,0 +1,212 @@
+package jsonport
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+const (
+	defaultBufferSize    = 4096
+	defaultMaxBufferSize = 64 << 20
+)
+
+var newline = []byte("\n")
+
+// ErrTooLong is returned by Decoder when a JSON value does not fit in the buffer.
+var ErrTooLong = errors.New("JSON value too long for the decoder buffer")
+
+// A Decoder reads and decodes JSON values from an input stream.
+// Values may be separated by whitespace or simply concatenated,
+// like `{"a":1}{"a":2}` or `1 2 3`.
+//
+// The input is read into a buffer bounded by Buffer,
+// only the bytes of the value being decoded are kept in memory.
+type Decoder struct {
+	r io.Reader
+	p parser
+
+	buf  []byte
+	scan int // start of the unread data in buf
+	max  int
+
+	off   int64 // input offset of buf[0]
+	lines int   // lines before buf[scan]
+	col   int   // column of buf[scan] in the current line, 0-based
+
+	err error // error from r, it is io.EOF at the end of the input
+}
+
+// NewDecoder returns a new decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return (*Options)(nil).NewDecoder(r)
+}
+
+// NewDecoder is like the package level NewDecoder,
+// but values are parsed with options o.
+// MaxBytes of o limits the length of each value instead of the whole input.
+func (o *Options) NewDecoder(r io.Reader) *Decoder {
+	d := &Decoder{r: r, max: defaultMaxBufferSize}
+	if o != nil {
+		d.p.opt = *o
+	}
+	return d
+}
+
+// Buffer sets the initial size of the buffer and the maximum size of it.
+// A value larger than max fails with ErrTooLong.
+// By default the buffer starts at 4KB and is limited to 64MB.
+// Buffer must be called before the first Decode or More.
+func (d *Decoder) Buffer(size, max int) {
+	if size > max {
+		size = max
+	}
+	d.buf = make([]byte, 0, size)
+	d.max = max
+}
+
+// InputOffset returns the input stream byte offset of the current decoder position,
+// the offset right after the last decoded value.
+func (d *Decoder) InputOffset() int64 {
+	return d.off + int64(d.scan)
+}
+
+// More reports whether there is another value in the input.
+func (d *Decoder) More() bool {
+	for {
+		b := d.buf[d.scan:]
+		n := d.p.skipspace(b)
+		if d.p.opt.JSON5 && d.err == nil && len(b) > 0 && (n == len(b) || b[n] == '/') {
+			// a comment of JSON5 may not be completely read yet.
+			d.fill()
+			continue
+		}
+		d.advance(n)
+		if n < len(b) {
+			return true
+		}
+		if d.err != nil || d.fill() != nil {
+			return false
+		}
+	}
+}
+
+// Decode reads the next JSON value from the input.
+// io.EOF is returned if there are no more values.
+//
+// The returned Json refers to the buffer of d,
+// the bytes are never overwritten by later calls of Decode.
+func (d *Decoder) Decode() (Json, error) {
+	if !d.More() {
+		if d.err == io.EOF {
+			return Json{}, io.EOF
+		}
+		return Json{}, d.err
+	}
+	for {
+		b := d.buf[d.scan:]
+		d.p.data = b
+		n, err := d.p.jsonskip(b)
+		// an error at the end of b or a number ending at the end of b
+		// may be caused by a value not completely read yet.
+		more := n == len(b) && d.p.first(b) != '"' && b[0] != '{' && b[0] != '[' && b[0] != 'n' && b[0] != 't' && b[0] != 'f'
+		if err != nil {
+			var e *SyntaxError
+			more = errors.As(err, &e) && e.Offset == int64(len(b))
+			// or by a comment of JSON5 not completely read yet.
+			more = more || d.p.opt.JSON5 && e != nil && b[e.Offset] == '/'
+		}
+		if max := d.p.opt.MaxBytes; max > 0 && (n > max || more && len(b) > max) {
+			return Json{}, d.located(&LimitError{Limit: "MaxBytes", Max: max, Offset: int64(max)})
+		}
+		if more && d.err == nil {
+			if d.fill() == nil {
+				continue
+			}
+			if d.err != io.EOF {
+				return Json{}, d.err
+			}
+			continue
+		}
+		if err != nil {
+			return Json{}, d.located(err)
+		}
+		d.p.data = b[:n]
+		j, _, err := d.p.parse(d.p.data)
+		if err != nil {
+			return j, d.located(err)
+		}
+		d.advance(n)
+		j.nm = d.p.opt.MatchNames
+		j.sp = d.p.opt.InvalidStrings
+		j.json5 = d.p.opt.JSON5
+		return j, nil
+	}
+}
+
+// advance consumes n bytes of the unread data.
+func (d *Decoder) advance(n int) {
+	b := d.buf[d.scan : d.scan+n]
+	if k := bytes.Count(b, newline); k > 0 {
+		d.lines += k
+		d.col = len(b) - bytes.LastIndexByte(b, '\n') - 1
+	} else {
+		d.col += len(b)
+	}
+	d.scan += n
+}
+
+// located converts the offset, line and column of a *SyntaxError or *LimitError
+// from the unread data to the whole input.
+func (d *Decoder) located(err error) error {
+	var le *LimitError
+	if errors.As(err, &le) {
+		le.Offset += d.InputOffset()
+	}
+	var e *SyntaxError
+	if errors.As(err, &e) {
+		e.Offset += d.InputOffset()
+		if e.Line == 1 {
+			e.Column += d.col
+		}
+		e.Line += d.lines
+	}
+	return err
+}
+
+// fill reads more data into the buffer.
+// The bytes before d.scan may be referenced by decoded values,
+// so the unread data is moved to a new buffer instead of the front of buf.
+func (d *Decoder) fill() error {
+	if d.buf == nil {
+		d.buf = make([]byte, 0, defaultBufferSize)
+	}
+	if len(d.buf) == cap(d.buf) {
+		unread := len(d.buf) - d.scan
+		size := cap(d.buf)
+		if unread*2 > size {
+			size *= 2
+		}
+		if size > d.max {
+			size = d.max
+		}
+		if unread >= size {
+			d.err = ErrTooLong
+			return d.err
+		}
+		b := make([]byte, unread, size)
+		copy(b, d.buf[d.scan:])
+		d.off += int64(d.scan)
+		d.buf = b
+		d.scan = 0
+	}
+	n, err := d.r.Read(d.buf[len(d.buf):cap(d.buf)])
+	d.buf = d.buf[:len(d.buf)+n]
+	if err != nil {
+		d.err = err
+	}
+	if n == 0 {
+		return d.err
+	}
+	return nil
+}
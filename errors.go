@@ -1,15 +1,287 @@
 package jsonport
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 var (
 	errArrayIndex = errors.New("not array index")
 	errMemberName = errors.New("not member name")
-	errLen        = errors.New("not supported Len()")
-	errKeyType    = errors.New("key type err")
+)
+
+var (
+	// ErrKeyType is returned for a key of Get or Unmarshal which is
+	// neither a string nor an integer.
+	ErrKeyType = errors.New("key type err")
+
+	// ErrLen is returned by Len for BOOL, NUMBER and NULL.
+	ErrLen = errors.New("not supported Len()")
+
+	// ErrEachOf is wrapped by the error of EachOf or Each for a value
+	// which is neither an OBJECT nor an ARRAY.
+	ErrEachOf = errors.New("not supported EachOf()")
+
+	// ErrUnsupportedType is wrapped by the error of As and Get
+	// for a type which can not be converted to.
+	ErrUnsupportedType = errors.New("unsupported type")
+
+	// ErrUnexpectedEOF is wrapped by all the EOF errors below,
+	// they are wrapped by a *SyntaxError for the input ends too early.
+	ErrUnexpectedEOF = errors.New("unexpect EOF")
+
+	ErrJSONEOF   = fmt.Errorf("JSON: %w", ErrUnexpectedEOF)
+	ErrArrayEOF  = fmt.Errorf("ARRAY: %w", ErrUnexpectedEOF)
+	ErrObjectEOF = fmt.Errorf("OBJECT: %w", ErrUnexpectedEOF)
+	ErrStringEOF = fmt.Errorf("STRING: %w", ErrUnexpectedEOF)
+
+	// ErrInvalidUTF8 is wrapped by the error for a string which is not valid UTF-8
+	// in strict mode, or by String and the like, see StringPolicy.
+	ErrInvalidUTF8 = errors.New("invalid UTF-8")
 
-	errJSONEOF   = errors.New("JSON: unexpect EOF")
-	errArrayEOF  = errors.New("ARRAY: unexpect EOF")
-	errObjectEOF = errors.New("OBJECT: unexpect EOF")
-	errStringEOF = errors.New("STRING: unexpect EOF")
+	// ErrInvalidString is wrapped by the error of String and the like for a string
+	// with an invalid escape, a lone surrogate or a raw control character, see StringPolicy.
+	ErrInvalidString = errors.New("invalid string")
 )
+
+// ErrMissing is wrapped by the error of Extractor for a value not present.
+var ErrMissing = errors.New("missing")
+
+// Errors is a list of errors, returned by Extractor.Err.
+type Errors []error
+
+func (e Errors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d errors: ", len(e))
+	for i, err := range e {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap returns the errors, for errors.Is and errors.As of Go 1.20 and later.
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+// A TypeMismatchError is returned when a value is converted to,
+// or looked up as, a type other than its own, like Int() of a STRING
+// or Member() of an ARRAY.
+type TypeMismatchError struct {
+	Expected Type
+	Found    Type
+	Path     []interface{} // keys to the value from where the lookup started, if known
+}
+
+// withPath returns err with keys prepended to the path of it if it is a *TypeMismatchError.
+func withPath(err error, keys ...interface{}) error {
+	e, ok := err.(*TypeMismatchError)
+	if !ok || len(keys) == 0 {
+		return err
+	}
+	c := *e
+	c.Path = make([]interface{}, 0, len(keys)+len(e.Path))
+	c.Path = append(append(c.Path, keys...), e.Path...)
+	return &c
+}
+
+// atPath adds keys to err, to the Path of a *TypeMismatchError,
+// or to the message of other errors like `users[0].age: err`.
+func atPath(err error, keys []interface{}) error {
+	if len(keys) == 0 {
+		return err
+	}
+	switch e := err.(type) {
+	case *TypeMismatchError:
+		return withPath(err, keys...)
+	case *pathError:
+		c := *e
+		c.path = append(append(make([]interface{}, 0, len(keys)+len(e.path)), keys...), e.path...)
+		return &c
+	}
+	return &pathError{path: keys, err: err}
+}
+
+// pathError is err of the value at path, see atPath.
+type pathError struct {
+	path []interface{}
+	err  error
+}
+
+func (e *pathError) Error() string {
+	return formatPath(e.path) + ": " + e.err.Error()
+}
+
+func (e *pathError) Unwrap() error {
+	return e.err
+}
+
+func (e *TypeMismatchError) Error() string {
+	s := fmt.Sprintf("type mismatch: expected %s, found %s", e.Expected, e.Found)
+	if len(e.Path) != 0 {
+		return formatPath(e.Path) + ": " + s
+	}
+	return s
+}
+
+// A RangeError is returned when a number does not fit in the type requested,
+// like 300 for Json.Uint8 or 1e100000000 for Json.BigInt. It wraps strconv.ErrRange.
+type RangeError struct {
+	Num  Number // the number
+	Type string // the type requested, like "uint8"
+}
+
+func (e *RangeError) Error() string {
+	return fmt.Sprintf("number %s out of range of %s", e.Num, e.Type)
+}
+
+func (e *RangeError) Unwrap() error {
+	return strconv.ErrRange
+}
+
+// ErrNotInteger is wrapped by the error for a number with a fraction
+// where an integer is expected, like 1.5 for Json.BigInt.
+var ErrNotInteger = errors.New("not an integer")
+
+// ErrDuplicateKey is wrapped by the error for members of the same name
+// in an object parsed with DuplicateReject.
+var ErrDuplicateKey = errors.New("duplicate member name")
+
+// A LimitError is returned when the input exceeds one of the limits of Options.
+type LimitError struct {
+	Limit  string // name of the limit, like "MaxDepth"
+	Max    int    // value of the limit
+	Offset int64  // byte offset in the input where the limit is exceeded
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("limit exceeded at offset %d: %s %d", e.Offset, e.Limit, e.Max)
+}
+
+// A SyntaxError describes malformed JSON text and where it was found.
+type SyntaxError struct {
+	Offset int64 // byte offset of the error in the input
+	Line   int   // 1-based line number of Offset
+	Column int   // 1-based column of Offset, counted in bytes
+
+	// Path is the path of the innermost container being parsed,
+	// using the same keys as Get: string for members and int for elements.
+	Path []interface{}
+
+	// Snippet is the input line around Offset,
+	// followed by a second line with a caret pointing at Offset.
+	Snippet string
+
+	Err error // the description of the error
+}
+
+func (e *SyntaxError) Error() string {
+	s := fmt.Sprintf("syntax error at line %d, column %d (offset %d)", e.Line, e.Column, e.Offset)
+	if len(e.Path) != 0 {
+		s += " in " + formatPath(e.Path)
+	}
+	return s + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+const snippetContext = 24 // bytes of input shown before and after the error
+
+func newSyntaxError(data []byte, off int, err error) *SyntaxError {
+	e := &SyntaxError{Offset: int64(off), Line: 1, Err: err}
+	if off > len(data) {
+		off = len(data)
+	}
+	start := 0 // start of the line
+	for i, c := range data[:off] {
+		if c == '\n' {
+			e.Line++
+			start = i + 1
+		}
+	}
+	e.Column = off - start + 1
+
+	end := off // end of the line
+	for end < len(data) && data[end] != '\n' && data[end] != '\r' {
+		end++
+	}
+	if off-start > snippetContext {
+		start = off - snippetContext
+	}
+	if end-off > snippetContext {
+		end = off + snippetContext
+	}
+	caret := strings.Map(func(r rune) rune {
+		if r == '\t' {
+			return r
+		}
+		return ' '
+	}, string(data[start:off]))
+	e.Snippet = string(data[start:end]) + "\n" + caret + "^"
+	return e
+}
+
+// within prepends the path segment k to the path of err if it is a *SyntaxError.
+// k is a member name in []byte as it is in the input, or an array index.
+func within(err error, k interface{}) error {
+	e, ok := err.(*SyntaxError)
+	if !ok {
+		return err
+	}
+	if b, ok := k.([]byte); ok {
+		k = string(append([]byte(nil), unquote(b)...))
+	}
+	e.Path = append([]interface{}{k}, e.Path...)
+	return e
+}
+
+// formatPath formats keys like `users[3].address`.
+func formatPath(keys []interface{}) string {
+	var b strings.Builder
+	for _, k := range keys {
+		if s, ok := k.(string); ok {
+			if s == Each {
+				b.WriteString("[*]")
+			} else if isIdent(s) {
+				if b.Len() != 0 {
+					b.WriteByte('.')
+				}
+				b.WriteString(s)
+			} else {
+				b.WriteByte('[')
+				b.WriteString(strconv.Quote(s))
+				b.WriteByte(']')
+			}
+			continue
+		}
+		fmt.Fprintf(&b, "[%v]", k)
+	}
+	return b.String()
+}
+
+func isIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		if c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+			continue
+		}
+		if i > 0 && c >= '0' && c <= '9' {
+			continue
+		}
+		return false
+	}
+	return true
+}
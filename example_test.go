@@ -12,24 +12,24 @@ func Example_usage() {
 	}`
 	j, _ := Unmarshal([]byte(jsonstr))
 
-	// Output: Tom
+	// Tom
 	fmt.Println(j.GetString("users", 0, "name"))
 
-	// Output: [golang json]
+	// [golang json]
 	fmt.Println(j.Get("keywords").StringArray())
 
-	// Output: [Tom Peter]
+	// [Tom Peter]
 	names, _ := j.Get("users").EachOf("name").StringArray()
 	fmt.Println(names)
 
 	// try parse STRING as NUMBER
 	j.StringAsNumber()
-	// Output: 1438194274
+	// 1438194274
 	fmt.Println(j.Get("timestamp").Int())
 
 	// convert NUMBER, STRING, ARRAY and OBJECT type to BOOL
 	j.AllAsBool()
-	// Output: false
+	// false
 	fmt.Println(j.GetBool("status"))
 
 	// using Unmarshal with path which can speed up json decode
@@ -59,7 +59,7 @@ func ExampleJson_StringAsNumber() {
 	fmt.Println(n, err)
 	// Output:
 	// Without StringAsNumber():
-	// 0 type mismatch: expected NUMBER, found STRING
+	// 0 timestamp: type mismatch: expected NUMBER, found STRING
 	// With StringAsNumber():
 	// 1438194274 <nil>
 }
@@ -78,7 +78,7 @@ func ExampleJson_AllAsBool() {
 	fmt.Println(b, err)
 	// Output:
 	// Without AllAsBool():
-	// false type mismatch: expected BOOL, found NUMBER
+	// false enabled: type mismatch: expected BOOL, found NUMBER
 	// With AllAsBool():
 	// true <nil>
 
@@ -0,0 +1,148 @@
+package jsonport
+
+// An Extractor reads values from a Json one by one, and collects the errors
+// of all of them instead of stopping at the first one:
+//
+//	x := j.Extract()
+//	name := x.String("name")
+//	age := x.Int("age")
+//	if err := x.Err(); err != nil {
+//		// err lists every missing, mistyped or out of range value with its path
+//	}
+//
+// Values returned for errors are zero values. A value must be present,
+// a null is an error unless it is read by Get or the conversion accepts it.
+type Extractor struct {
+	j    Json
+	errs []error
+}
+
+// Extract returns an Extractor for the values in j.
+// The Coercion of j applies to them.
+func (j Json) Extract() *Extractor {
+	return &Extractor{j: j}
+}
+
+// Err returns an Errors of all the errors collected, or nil if there is none.
+func (x *Extractor) Err() error {
+	if len(x.errs) == 0 {
+		return nil
+	}
+	return append(Errors(nil), x.errs...)
+}
+
+// get returns the value at keys and true, or records the error of it.
+func (x *Extractor) get(keys []interface{}) (Json, bool) {
+	v, ok := x.j.Lookup(keys...)
+	if !ok {
+		if v.err != nil {
+			x.errs = append(x.errs, v.err) // with the path by Get
+		} else {
+			x.fail(ErrMissing, keys)
+		}
+	}
+	return v, ok
+}
+
+// fail records err of the value at keys.
+func (x *Extractor) fail(err error, keys []interface{}) {
+	if err == nil {
+		return
+	}
+	x.errs = append(x.errs, atPath(err, keys))
+}
+
+// Get returns the value at keys, which may be of any type including NULL.
+func (x *Extractor) Get(keys ...interface{}) Json {
+	v, _ := x.get(keys)
+	return v
+}
+
+// String returns the value at keys as a string, see Json.String.
+func (x *Extractor) String(keys ...interface{}) string {
+	v, ok := x.get(keys)
+	if !ok {
+		return ""
+	}
+	s, err := v.String()
+	x.fail(err, keys)
+	return s
+}
+
+// Bool returns the value at keys as a bool, see Json.Bool.
+func (x *Extractor) Bool(keys ...interface{}) bool {
+	v, ok := x.get(keys)
+	if !ok {
+		return false
+	}
+	b, err := v.Bool()
+	x.fail(err, keys)
+	return b
+}
+
+// Float returns the value at keys as a float64, see Json.Float.
+func (x *Extractor) Float(keys ...interface{}) float64 {
+	v, ok := x.get(keys)
+	if !ok {
+		return 0
+	}
+	f, err := v.Float()
+	x.fail(err, keys)
+	return f
+}
+
+// Int returns the value at keys as an int64, see Json.Int64.
+// Unlike Json.Int, numbers out of range and fractions are errors.
+func (x *Extractor) Int(keys ...interface{}) int64 {
+	v, ok := x.get(keys)
+	if !ok {
+		return 0
+	}
+	n, err := v.Int64()
+	x.fail(err, keys)
+	return n
+}
+
+// Int32 returns the value at keys as an int32, see Json.Int32.
+func (x *Extractor) Int32(keys ...interface{}) int32 {
+	v, ok := x.get(keys)
+	if !ok {
+		return 0
+	}
+	n, err := v.Int32()
+	x.fail(err, keys)
+	return n
+}
+
+// Uint returns the value at keys as a uint64, see Json.Uint.
+func (x *Extractor) Uint(keys ...interface{}) uint64 {
+	v, ok := x.get(keys)
+	if !ok {
+		return 0
+	}
+	n, err := v.Uint()
+	x.fail(err, keys)
+	return n
+}
+
+// StringArray returns the value at keys as a []string, see Json.StringArray.
+func (x *Extractor) StringArray(keys ...interface{}) []string {
+	v, ok := x.get(keys)
+	if !ok {
+		return nil
+	}
+	a, err := v.StringArray()
+	x.fail(err, keys)
+	return a
+}
+
+// IntArray returns the value at keys as a []int64, see Json.IntArray.
+func (x *Extractor) IntArray(keys ...interface{}) []int64 {
+	v, ok := x.get(keys)
+	if !ok {
+		return nil
+	}
+	a, err := v.IntArray()
+	x.fail(err, keys)
+	return a
+}
@@ -0,0 +1,157 @@
+package jsonport
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+)
+
+var (
+	jsonType     = reflect.TypeOf(Json{})
+	numberType   = reflect.TypeOf(Number(""))
+	bigIntType   = reflect.TypeOf((*big.Int)(nil))
+	bigFloatType = reflect.TypeOf((*big.Float)(nil))
+	ratType      = reflect.TypeOf((*big.Rat)(nil))
+)
+
+// As converts j to a value of type T, which is one of
+//
+//	bool, string, float32, float64 and the integer types, or types defined on them,
+//	Number, *big.Int, *big.Float, *big.Rat and Json,
+//	a slice of T, converted from an ARRAY by Array,
+//	a pointer to T, nil for NULL,
+//	a map[string]T, converted from an OBJECT, the first of duplicate names wins.
+//
+// So types like [][]float64, []*string and map[string][]int are supported.
+//
+// Integers are converted like Int32 and the like, a *RangeError is returned
+// instead of wrapping. The Coercion of j applies to all the values converted,
+// and the error of an element or a member has the path to it,
+// like `[1].name: type mismatch: expected STRING, found NUMBER`.
+func As[T any](j Json) (T, error) {
+	var ret T
+	err := j.decode(reflect.ValueOf(&ret).Elem())
+	return ret, err
+}
+
+// Get converts json value specified by keys to T,
+// it is equal to As[T](j.Get(keys...))
+func Get[T any](j Json, keys ...interface{}) (T, error) {
+	v := j.Get(keys...)
+	ret, err := As[T](v)
+	return ret, v.at(err, keys)
+}
+
+// decode converts j to v, see As.
+func (j Json) decode(v reflect.Value) error {
+	switch v.Type() {
+	case jsonType:
+		v.Set(reflect.ValueOf(j))
+		return nil
+	case numberType:
+		n, err := j.number()
+		v.SetString(string(n))
+		return err
+	case bigIntType:
+		x, err := j.BigInt()
+		v.Set(reflect.ValueOf(x))
+		return err
+	case bigFloatType:
+		x, err := j.BigFloat()
+		v.Set(reflect.ValueOf(x))
+		return err
+	case ratType:
+		x, err := j.Rat()
+		v.Set(reflect.ValueOf(x))
+		return err
+	}
+	switch k := v.Kind(); k {
+	case reflect.Bool:
+		b, err := j.Bool()
+		v.SetBool(b)
+		return err
+	case reflect.String:
+		s, err := j.String()
+		v.SetString(s)
+		return err
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := j.signed(k.String(), uint(v.Type().Bits()))
+		v.SetInt(i)
+		return err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := j.unsigned(k.String(), uint(v.Type().Bits()))
+		v.SetUint(u)
+		return err
+	case reflect.Float32, reflect.Float64:
+		f, err := j.Float()
+		if err == nil && v.OverflowFloat(f) {
+			n, _ := j.number()
+			return &RangeError{Num: n, Type: k.String()}
+		}
+		v.SetFloat(f)
+		return err
+	case reflect.Ptr:
+		if j.err == nil && j.null() {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		p := reflect.New(v.Type().Elem())
+		if err := j.decode(p.Elem()); err != nil {
+			return err
+		}
+		v.Set(p)
+		return nil
+	case reflect.Slice:
+		arr, err := j.Array()
+		if err != nil {
+			return err
+		}
+		s := reflect.MakeSlice(v.Type(), len(arr), len(arr))
+		for i, e := range arr {
+			if err := j.returnj(e).decode(s.Index(i)); err != nil {
+				return atPath(err, []interface{}{i})
+			}
+		}
+		v.Set(s)
+		return nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			break
+		}
+		return j.decodeMap(v)
+	}
+	return fmt.Errorf("%s: %w", v.Type(), ErrUnsupportedType)
+}
+
+// decodeMap converts j to the map v of string keys.
+func (j Json) decodeMap(v reflect.Value) error {
+	t := v.Type()
+	if j.tp != OBJECT {
+		if j.zero() {
+			v.Set(reflect.MakeMap(t))
+			return nil
+		}
+		return j.mismatch(OBJECT)
+	}
+	if err := j.load(); err != nil {
+		return err
+	}
+	m := reflect.MakeMapWithSize(t, len(j.m))
+	for i := range j.m {
+		name, err := j.m[i].name(j.sp, j.json5)
+		if err != nil {
+			return err
+		}
+		k := reflect.ValueOf(name).Convert(t.Key())
+		if m.MapIndex(k).IsValid() {
+			continue
+		}
+		e := reflect.New(t.Elem()).Elem()
+		if err := j.returnj(j.m[i].v).decode(e); err != nil {
+			return atPath(err, []interface{}{name})
+		}
+		m.SetMapIndex(k, e)
+	}
+	v.Set(m)
+	return nil
+}
@@ -9,3 +9,16 @@ func ss(b []byte) string {
 func nn(b []byte) Number {
 	return *(*Number)(unsafe.Pointer(&b))
 }
+
+// offsetIn returns the offset of b in buf, or -1 if b is not a part of buf.
+func offsetIn(b, buf []byte) int {
+	if len(b) == 0 || len(buf) == 0 {
+		return -1
+	}
+	p := uintptr(unsafe.Pointer(&b[0]))
+	q := uintptr(unsafe.Pointer(&buf[0]))
+	if p < q || p+uintptr(len(b)) > q+uintptr(len(buf)) {
+		return -1
+	}
+	return int(p - q)
+}
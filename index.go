@@ -0,0 +1,47 @@
+package jsonport
+
+import "sync"
+
+// indexMembers is the number of members from which an OBJECT
+// is looked up by a hash index instead of a linear scan.
+const indexMembers = 32
+
+// memberIndex maps the member names of an OBJECT to their positions,
+// it is built on the first lookup and shared by all copies of the Json.
+type memberIndex struct {
+	once sync.Once
+	pos  map[string]int
+	bad  int // position of the first invalid member name or -1, see kv.name
+}
+
+// newMemberIndex returns an index for the members m, or nil if m is small.
+func newMemberIndex(m []kv) *memberIndex {
+	if len(m) < indexMembers {
+		return nil
+	}
+	return &memberIndex{}
+}
+
+// build builds the index for the members m once, and returns x.
+// json5 is whether the object is parsed with Options.JSON5.
+func (x *memberIndex) build(m []kv, json5 bool) *memberIndex {
+	x.once.Do(func() {
+		x.pos = make(map[string]int, len(m))
+		x.bad = -1
+		for i := len(m) - 1; i >= 0; i-- {
+			x.pos[m[i].key(json5)] = i
+			if m[i].bad {
+				x.bad = i
+			}
+		}
+	})
+	return x
+}
+
+// lookup returns the position of the first member of m named name or -1.
+func (x *memberIndex) lookup(m []kv, name string, json5 bool) int {
+	if i, ok := x.build(m, json5).pos[name]; ok {
+		return i
+	}
+	return -1
+}
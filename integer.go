@@ -0,0 +1,324 @@
+package jsonport
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+)
+
+// signed converts current json value to an integer of type t of bits,
+// error is returned instead of wrapping or truncating the number.
+func (j Json) signed(t string, bits uint) (int64, error) {
+	n, err := j.number()
+	if err != nil {
+		return 0, err
+	}
+	i, err := n.Int64()
+	if err != nil {
+		x, err := j.integer(n, t)
+		if err != nil {
+			return 0, err
+		}
+		if !x.IsInt64() {
+			return 0, &RangeError{Num: n, Type: t}
+		}
+		i = x.Int64()
+	}
+	if bits < 64 && (i < -1<<(bits-1) || i > 1<<(bits-1)-1) {
+		return 0, &RangeError{Num: n, Type: t}
+	}
+	return i, nil
+}
+
+// unsigned is like signed for unsigned integers.
+func (j Json) unsigned(t string, bits uint) (uint64, error) {
+	n, err := j.number()
+	if err != nil {
+		return 0, err
+	}
+	u, err := n.Uint64()
+	if err != nil {
+		x, err := j.integer(n, t)
+		if err != nil {
+			return 0, err
+		}
+		if !x.IsUint64() {
+			return 0, &RangeError{Num: n, Type: t}
+		}
+		u = x.Uint64()
+	}
+	if bits < 64 && u > 1<<bits-1 {
+		return 0, &RangeError{Num: n, Type: t}
+	}
+	return u, nil
+}
+
+// integer returns n as a *big.Int for the numbers not parsed by strconv,
+// like 1e3, 1.5 or 1<<64. Fractions are truncated only if TruncateFraction is enabled.
+func (j Json) integer(n Number, t string) (*big.Int, error) {
+	r, err := n.Rat()
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return nil, &RangeError{Num: n, Type: t}
+		}
+		return nil, n.syntaxError(t)
+	}
+	if r.IsInt() {
+		return r.Num(), nil
+	}
+	if j.co&FractionToInt == 0 {
+		return nil, &strconv.NumError{Func: t, Num: string(n), Err: ErrNotInteger}
+	}
+	return new(big.Int).Quo(r.Num(), r.Denom()), nil
+}
+
+// Uint converts current json value to uint64.
+// Unlike Int, a *RangeError is returned if the number is negative or too large,
+// and an error wrapping ErrNotInteger is returned for numbers like 1.5,
+// unless TruncateFraction is enabled.
+// Int64, Int32, Int16, Int8, Uint32, Uint16 and Uint8 do the same.
+func (j Json) Uint() (uint64, error) {
+	return j.unsigned("uint64", 64)
+}
+
+// Int64 converts current json value to int64, see Uint.
+// Unlike Int, numbers like 1<<63 are not wrapped.
+func (j Json) Int64() (int64, error) {
+	return j.signed("int64", 64)
+}
+
+// Int32 converts current json value to int32, see Uint.
+func (j Json) Int32() (int32, error) {
+	i, err := j.signed("int32", 32)
+	return int32(i), err
+}
+
+// Int16 converts current json value to int16, see Uint.
+func (j Json) Int16() (int16, error) {
+	i, err := j.signed("int16", 16)
+	return int16(i), err
+}
+
+// Int8 converts current json value to int8, see Uint.
+func (j Json) Int8() (int8, error) {
+	i, err := j.signed("int8", 8)
+	return int8(i), err
+}
+
+// Uint32 converts current json value to uint32, see Uint.
+func (j Json) Uint32() (uint32, error) {
+	u, err := j.unsigned("uint32", 32)
+	return uint32(u), err
+}
+
+// Uint16 converts current json value to uint16, see Uint.
+func (j Json) Uint16() (uint16, error) {
+	u, err := j.unsigned("uint16", 16)
+	return uint16(u), err
+}
+
+// Uint8 converts current json value to uint8, see Uint.
+func (j Json) Uint8() (uint8, error) {
+	u, err := j.unsigned("uint8", 8)
+	return uint8(u), err
+}
+
+// GetUint convert json value specified by keys to uint64,
+// it is equal to Get(keys...).Uint()
+func (j Json) GetUint(keys ...interface{}) (uint64, error) {
+	v := j.Get(keys...)
+	ret, err := v.Uint()
+	return ret, v.at(err, keys)
+}
+
+// GetInt64 convert json value specified by keys to int64,
+// it is equal to Get(keys...).Int64()
+func (j Json) GetInt64(keys ...interface{}) (int64, error) {
+	v := j.Get(keys...)
+	ret, err := v.Int64()
+	return ret, v.at(err, keys)
+}
+
+// GetInt32 convert json value specified by keys to int32,
+// it is equal to Get(keys...).Int32()
+func (j Json) GetInt32(keys ...interface{}) (int32, error) {
+	v := j.Get(keys...)
+	ret, err := v.Int32()
+	return ret, v.at(err, keys)
+}
+
+// GetInt16 convert json value specified by keys to int16,
+// it is equal to Get(keys...).Int16()
+func (j Json) GetInt16(keys ...interface{}) (int16, error) {
+	v := j.Get(keys...)
+	ret, err := v.Int16()
+	return ret, v.at(err, keys)
+}
+
+// GetInt8 convert json value specified by keys to int8,
+// it is equal to Get(keys...).Int8()
+func (j Json) GetInt8(keys ...interface{}) (int8, error) {
+	v := j.Get(keys...)
+	ret, err := v.Int8()
+	return ret, v.at(err, keys)
+}
+
+// GetUint32 convert json value specified by keys to uint32,
+// it is equal to Get(keys...).Uint32()
+func (j Json) GetUint32(keys ...interface{}) (uint32, error) {
+	v := j.Get(keys...)
+	ret, err := v.Uint32()
+	return ret, v.at(err, keys)
+}
+
+// GetUint16 convert json value specified by keys to uint16,
+// it is equal to Get(keys...).Uint16()
+func (j Json) GetUint16(keys ...interface{}) (uint16, error) {
+	v := j.Get(keys...)
+	ret, err := v.Uint16()
+	return ret, v.at(err, keys)
+}
+
+// GetUint8 convert json value specified by keys to uint8,
+// it is equal to Get(keys...).Uint8()
+func (j Json) GetUint8(keys ...interface{}) (uint8, error) {
+	v := j.Get(keys...)
+	ret, err := v.Uint8()
+	return ret, v.at(err, keys)
+}
+
+// UintArray converts current json value to []uint64.
+// error is returned if any element can not be converted by Uint.
+func (j Json) UintArray() ([]uint64, error) {
+	arr, err := j.Array()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]uint64, len(arr))
+	for i, e := range arr {
+		ret[i], err = j.returnj(e).Uint()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// Int64Array converts current json value to []int64.
+// error is returned if any element can not be converted by Int64.
+func (j Json) Int64Array() ([]int64, error) {
+	arr, err := j.Array()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]int64, len(arr))
+	for i, e := range arr {
+		ret[i], err = j.returnj(e).Int64()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// Int32Array converts current json value to []int32.
+// error is returned if any element can not be converted by Int32.
+func (j Json) Int32Array() ([]int32, error) {
+	arr, err := j.Array()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]int32, len(arr))
+	for i, e := range arr {
+		ret[i], err = j.returnj(e).Int32()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// Int16Array converts current json value to []int16.
+// error is returned if any element can not be converted by Int16.
+func (j Json) Int16Array() ([]int16, error) {
+	arr, err := j.Array()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]int16, len(arr))
+	for i, e := range arr {
+		ret[i], err = j.returnj(e).Int16()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// Int8Array converts current json value to []int8.
+// error is returned if any element can not be converted by Int8.
+func (j Json) Int8Array() ([]int8, error) {
+	arr, err := j.Array()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]int8, len(arr))
+	for i, e := range arr {
+		ret[i], err = j.returnj(e).Int8()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// Uint32Array converts current json value to []uint32.
+// error is returned if any element can not be converted by Uint32.
+func (j Json) Uint32Array() ([]uint32, error) {
+	arr, err := j.Array()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]uint32, len(arr))
+	for i, e := range arr {
+		ret[i], err = j.returnj(e).Uint32()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// Uint16Array converts current json value to []uint16.
+// error is returned if any element can not be converted by Uint16.
+func (j Json) Uint16Array() ([]uint16, error) {
+	arr, err := j.Array()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]uint16, len(arr))
+	for i, e := range arr {
+		ret[i], err = j.returnj(e).Uint16()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// Uint8Array converts current json value to []uint8.
+// error is returned if any element can not be converted by Uint8.
+func (j Json) Uint8Array() ([]uint8, error) {
+	arr, err := j.Array()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]uint8, len(arr))
+	for i, e := range arr {
+		ret[i], err = j.returnj(e).Uint8()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
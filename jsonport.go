@@ -1,46 +1,67 @@
 package jsonport
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math/big"
 )
 
 const (
 	zero = Number("0")
 
 	ParseMemberNamesOnly = "__member_names_only__"
+
+	// Each is a key matching every element of an ARRAY or every member of an OBJECT.
+	// The values matched by the keys after it are returned as an ARRAY,
+	// j.Get("users", Each, "name") is equal to j.Get("users").EachOf("name").
+	// It works with Unmarshal as well, which skips all other fields.
+	Each = "__each__"
 )
 
 // Json represents everything of json
 type Json struct {
-	atoi bool
-	tob  bool
-	err  error
+	// the small fields are packed in one word, every value has them.
+	co      Coercion
+	nm      NameMatch
+	sp      StringPolicy
+	json5   bool // parsed with Options.JSON5, for the escapes of strings
+	missing bool // tp: NULL, see IsMissing
+	t       bool // tp: BOOL
+	err     error
 
 	tp Type
 
-	m []kv   // tp: OBJECT
-	a []Json // tp: ARRAY
-	b []byte // tp: NUMBER or STRING
-	t bool   // tp: BOOL
+	m  []kv         // tp: OBJECT
+	a  []Json       // tp: ARRAY
+	lz *lazy        // tp: OBJECT or ARRAY, not parsed yet
+	x  *memberIndex // tp: OBJECT, nil for small objects
+	b  []byte       // the value in the input, see Raw()
 }
 
 type kv struct {
-	s string
-	k []byte
-	v Json
+	s   string
+	k   []byte
+	v   Json
+	bad bool // k is invalid, s has U+FFFD in place of the invalid bytes
 }
 
-func (e *kv) key() string {
+// key returns the member name decoded with StringReplace, see name.
+// json5 is whether the object is parsed with Options.JSON5.
+func (e *kv) key(json5 bool) string {
 	if len(e.s) != 0 {
 		return e.s
 	}
 	if len(e.k) == 0 {
 		return ""
 	}
-	e.s = unquote(e.k)
+	var err error
+	if e.s, err = StringReject.unquote(e.k, json5); err != nil {
+		e.bad = true
+		e.s, _ = StringReplace.unquote(e.k, json5)
+	}
 	return e.s
 }
 
@@ -51,28 +72,68 @@ var (
 // Unmarshal parses data to Json.
 // When specified keys, jsonport skips unused field for performance
 func Unmarshal(data []byte, keys ...interface{}) (Json, error) {
+	return (*Options)(nil).Unmarshal(data, keys...)
+}
+
+// DecodeFrom parses data from reader to json
+func DecodeFrom(r io.Reader) (Json, error) {
+	return (*Options)(nil).DecodeFrom(r)
+}
+
+// UnmarshalContext is like Unmarshal, but aborts parsing with ctx.Err() once ctx is done.
+func UnmarshalContext(ctx context.Context, data []byte, keys ...interface{}) (Json, error) {
+	return (*Options)(nil).UnmarshalContext(ctx, data, keys...)
+}
+
+// Unmarshal is like the package level Unmarshal, but parses data with options o.
+// A nil *Options is the same as the zero value.
+func (o *Options) Unmarshal(data []byte, keys ...interface{}) (Json, error) {
+	return newParser(o, data).unmarshal(keys...)
+}
+
+// UnmarshalContext is like the package level UnmarshalContext, but parses data with options o.
+func (o *Options) UnmarshalContext(ctx context.Context, data []byte, keys ...interface{}) (Json, error) {
+	if err := ctx.Err(); err != nil {
+		return Json{}, err
+	}
+	p := newParser(o, data)
+	p.ctx = ctx
+	return p.unmarshal(keys...)
+}
+
+func (p *parser) unmarshal(keys ...interface{}) (Json, error) {
+	data := p.data
+	if max := p.opt.MaxBytes; max > 0 && len(data) > max {
+		return Json{}, &LimitError{Limit: "MaxBytes", Max: max, Offset: int64(max)}
+	}
 	if len(keys) != 0 {
-		j, _, err := parsePath(data, keys...)
+		j, _, err := p.parsePath(data, keys...)
+		j.nm = p.opt.MatchNames
+		j.sp = p.opt.InvalidStrings
+		j.json5 = p.opt.JSON5
 		return j, err
 	}
-	j, i, err := parse(data)
+	j, i, err := p.parse(data)
+	j.nm = p.opt.MatchNames
+	j.sp = p.opt.InvalidStrings
+	j.json5 = p.opt.JSON5
 	if err != nil {
 		return j, err
 	}
-	n := skipspace(data[i:])
+	n := p.skipspace(data[i:])
 	if n+i != len(data) {
 		return j, ErrMoreBytes
 	}
 	return j, nil
 }
 
-// DecodeFrom parses data from reader to json
-func DecodeFrom(r io.Reader) (Json, error) {
+// DecodeFrom is like the package level DecodeFrom, but parses data with options o.
+func (o *Options) DecodeFrom(r io.Reader) (Json, error) {
 	b, err := ioutil.ReadAll(r)
 	if err != nil {
-		return Json{}, nil
+		return Json{}, err
 	}
-	return Unmarshal(b)
+	return o.Unmarshal(b)
 }
 
 // Type returns the Type of current json value
@@ -101,7 +162,14 @@ func (j Json) IsBool() bool {
 }
 
 func (j Json) IsNull() bool {
-	return j.tp == NULL
+	return j.null()
+}
+
+// IsMissing reports whether current json value is a member not found
+// or an element out of range, rather than a null in the document.
+// Type() of the value is NULL as well.
+func (j Json) IsMissing() bool {
+	return j.missing
 }
 
 // Error returns error of current context
@@ -112,36 +180,71 @@ func (j Json) Error() error {
 // StringAsNumber enables conversion like {"id": "123"},
 // j.GetInt("id") is returned 123 instead of an err,
 // j.GetString("id") is returned "123" as expected.
+// It adds StringToNumber to the Coercion of j.
 func (j *Json) StringAsNumber() {
-	j.atoi = true
+	j.co |= StringToNumber
 }
 
 // AllAsBool enables conversion of Bool():
 //	STRING, ARRAY, OBJECT:	true if Len() != 0
 //	NUMBER:	true if Float() != 0
 //	NULL:	false
+// It adds AnyToBool to the Coercion of j.
 func (j *Json) AllAsBool() {
-	j.tob = true
+	j.co |= AnyToBool
+}
+
+// TruncateFraction enables Uint, Int32 and the like to truncate
+// numbers like 1.5 to 1 instead of returning an error wrapping ErrNotInteger.
+// It adds FractionToInt to the Coercion of j.
+func (j *Json) TruncateFraction() {
+	j.co |= FractionToInt
 }
 
 func (j Json) mismatch(t Type) error {
 	if j.err != nil {
 		return j.err
 	}
-	return fmt.Errorf("type mismatch: expected %s, found %s", t, j.tp)
+	return &TypeMismatchError{Expected: t, Found: j.tp}
 }
 
-// String converts current json value to string
+// String converts current json value to string.
+// error is returned for an invalid string, see StringPolicy.
 func (j Json) String() (string, error) {
-	if j.tp != STRING {
-		return "", j.mismatch(STRING)
+	switch {
+	case j.tp == STRING:
+		return j.sp.unquote(j.str(), j.json5)
+	case j.tp == NUMBER && j.co&NumberToString != 0:
+		return string(j.b), nil
+	case j.zero():
+		return "", nil
 	}
-	return unquote(j.b), nil
+	return "", j.mismatch(STRING)
+}
+
+// str returns the content of a STRING, the bytes between the quotes.
+func (j Json) str() []byte {
+	return j.b[1 : len(j.b)-1]
+}
+
+// Raw returns the bytes of the value as it is in the input,
+// such as `{"a": 1}`, `"a\tb"` with quotes and escapes, or `1.50`.
+// It returns nil for values not in the input,
+// like a NULL of missing member or an ARRAY made by EachOf.
+//
+// The bytes are shared with the input and must not be modified.
+func (j Json) Raw() []byte {
+	return j.b
 }
 
 func (j Json) number() (Number, error) {
-	if j.tp == NUMBER || (j.atoi && j.tp == STRING) {
+	switch {
+	case j.tp == NUMBER:
 		return nn(j.b), nil
+	case j.zero():
+		return zero, nil
+	case j.tp == STRING && j.co&StringToNumber != 0 && !j.null():
+		return nn(j.str()), nil
 	}
 	return zero, j.mismatch(NUMBER)
 }
@@ -173,12 +276,63 @@ func (j Json) Int() (int64, error) {
 	return i, err
 }
 
+// Number returns the literal of current json value, like 1.50 or 1e3.
+func (j Json) Number() (Number, error) {
+	return j.number()
+}
+
+// BigInt converts current json value to *big.Int without losing precision.
+// error is returned if the value is not an integer, see Number.BigInt.
+func (j Json) BigInt() (*big.Int, error) {
+	n, err := j.number()
+	if err != nil {
+		return nil, err
+	}
+	return n.BigInt()
+}
+
+// BigFloat converts current json value to *big.Float, see Number.BigFloat.
+func (j Json) BigFloat() (*big.Float, error) {
+	n, err := j.number()
+	if err != nil {
+		return nil, err
+	}
+	return n.BigFloat()
+}
+
+// Rat converts current json value to *big.Rat, the exact value of the decimal.
+func (j Json) Rat() (*big.Rat, error) {
+	n, err := j.number()
+	if err != nil {
+		return nil, err
+	}
+	return n.Rat()
+}
+
+// IsInteger reports whether current json value is a NUMBER of an integer,
+// like 1, 1.0 or 1e3.
+func (j Json) IsInteger() bool {
+	n, err := j.number()
+	return err == nil && n.IsInteger()
+}
+
 // Bool converts current json value to bool
 func (j Json) Bool() (bool, error) {
 	if j.tp == BOOL {
 		return j.t, nil
 	}
-	if !j.tob {
+	if j.tp == STRING && j.co&StringToBool != 0 {
+		switch string(j.str()) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+	}
+	if j.zero() {
+		return false, nil
+	}
+	if j.co&AnyToBool == 0 {
 		return false, j.mismatch(BOOL)
 	}
 	switch j.tp {
@@ -199,24 +353,38 @@ func (j Json) Bool() (bool, error) {
 func (j Json) Len() (int, error) {
 	switch j.tp {
 	case STRING:
-		return len(j.b), nil
+		return len(j.str()), nil
 	case ARRAY:
+		if err := j.load(); err != nil {
+			return 0, err
+		}
 		return len(j.a), nil
 	case OBJECT:
+		if err := j.load(); err != nil {
+			return 0, err
+		}
 		return len(j.m), nil
 	}
-	return 0, errLen
+	return 0, ErrLen
 }
 
 // Keys returns the field names of json object.
-// error is returned if value type not equal to OBJECT.
+// error is returned if value type not equal to OBJECT,
+// or for an invalid name, see StringPolicy.
 func (j Json) Keys() ([]string, error) {
 	if j.tp != OBJECT {
 		return nil, j.mismatch(OBJECT)
 	}
+	if err := j.load(); err != nil {
+		return nil, err
+	}
 	ret := make([]string, 0, len(j.m))
 	for i := range j.m {
-		ret = append(ret, j.m[i].key())
+		k, err := j.m[i].name(j.sp, j.json5)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, k)
 	}
 	return ret, nil
 }
@@ -227,6 +395,9 @@ func (j Json) Values() ([]Json, error) {
 	if j.tp != OBJECT {
 		return nil, j.mismatch(OBJECT)
 	}
+	if err := j.load(); err != nil {
+		return nil, err
+	}
 	ret := make([]Json, 0, len(j.m))
 	for i := range j.m {
 		ret = append(ret, j.returnj(j.m[i].v))
@@ -235,31 +406,52 @@ func (j Json) Values() ([]Json, error) {
 }
 
 // Member returns the member value specified by `name`
-// a NULL type Json is returned if member not found
-// Json.Error() is set if type not equal to OBJECT
+// a NULL type Json is returned if member not found, IsMissing reports it.
+// Json.Error() is set if type not equal to OBJECT,
+// or if the name of the member is invalid with StringReject, see StringPolicy.
 func (j Json) Member(name string) Json {
 	if j.tp != OBJECT {
 		return Json{err: j.mismatch(OBJECT)}
 	}
+	if err := j.load(); err != nil {
+		return Json{err: err}
+	}
+	if j.x != nil && j.nm == MatchExact && (j.sp != StringRaw || j.x.build(j.m, j.json5).bad < 0) {
+		if i := j.x.lookup(j.m, name, j.json5); i >= 0 {
+			if _, err := j.m[i].name(j.sp, j.json5); err != nil {
+				return Json{err: err}
+			}
+			return j.returnj(j.m[i].v)
+		}
+		return j.returnj(Json{tp: NULL, missing: true})
+	}
 	for i := range j.m {
-		if j.m[i].key() == name {
+		k, err := j.m[i].name(j.sp, j.json5)
+		if k == name || j.nm != MatchExact && j.nm.match(k, name) {
+			if err != nil {
+				return Json{err: err}
+			}
 			return j.returnj(j.m[i].v)
 		}
 	}
-	v := Json{tp: NULL}
+	v := Json{tp: NULL, missing: true}
 	return j.returnj(v)
 }
 
 // Element returns the (i+1)th element of array.
-// a NULL type Json is returned if index out of range.
+// a NULL type Json is returned if index out of range, IsMissing reports it.
 // Json.Error() is set if type not equal to ARRAY.
 func (j Json) Element(i int) Json {
 	if j.tp != ARRAY {
 		return Json{err: j.mismatch(ARRAY)}
 	}
+	if err := j.load(); err != nil {
+		return Json{err: err}
+	}
 	var v Json
 	if i < 0 || i >= len(j.a) {
 		v.tp = NULL
+		v.missing = true
 	} else {
 		v = j.a[i]
 	}
@@ -267,8 +459,10 @@ func (j Json) Element(i int) Json {
 }
 
 func (j *Json) returnj(v Json) Json {
-	v.atoi = j.atoi
-	v.tob = j.tob
+	v.co = j.co
+	v.nm = j.nm
+	v.sp = j.sp
+	v.json5 = j.json5
 	return v
 }
 
@@ -276,8 +470,17 @@ func (j *Json) returnj(v Json) Json {
 // error is returned if value type not equal to ARRAY.
 func (j Json) Array() ([]Json, error) {
 	if j.tp != ARRAY {
+		if j.zero() {
+			return []Json{}, nil
+		}
+		if j.co&SingleToArray != 0 && j.tp != INVALID && !j.null() {
+			return []Json{j}, nil
+		}
 		return nil, j.mismatch(ARRAY)
 	}
+	if err := j.load(); err != nil {
+		return nil, err
+	}
 	return j.a, nil
 }
 
@@ -290,7 +493,7 @@ func (j Json) IntArray() ([]int64, error) {
 	}
 	ret := make([]int64, len(arr))
 	for i, e := range arr {
-		n, err := e.Int()
+		n, err := j.returnj(e).Int()
 		if err != nil {
 			return nil, err
 		}
@@ -308,7 +511,7 @@ func (j Json) FloatArray() ([]float64, error) {
 	}
 	ret := make([]float64, len(arr))
 	for i, e := range arr {
-		n, err := e.Float()
+		n, err := j.returnj(e).Float()
 		if err != nil {
 			return nil, err
 		}
@@ -317,6 +520,57 @@ func (j Json) FloatArray() ([]float64, error) {
 	return ret, nil
 }
 
+// BigIntArray converts current json value to []*big.Int.
+// error is returned if any element type not equal to NUMBER or not an integer.
+func (j Json) BigIntArray() ([]*big.Int, error) {
+	arr, err := j.Array()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*big.Int, len(arr))
+	for i, e := range arr {
+		ret[i], err = j.returnj(e).BigInt()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// BigFloatArray converts current json value to []*big.Float.
+// error is returned if any element type not equal to NUMBER.
+func (j Json) BigFloatArray() ([]*big.Float, error) {
+	arr, err := j.Array()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*big.Float, len(arr))
+	for i, e := range arr {
+		ret[i], err = j.returnj(e).BigFloat()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// RatArray converts current json value to []*big.Rat.
+// error is returned if any element type not equal to NUMBER.
+func (j Json) RatArray() ([]*big.Rat, error) {
+	arr, err := j.Array()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*big.Rat, len(arr))
+	for i, e := range arr {
+		ret[i], err = j.returnj(e).Rat()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
 // BoolArray converts current json value to []bool.
 // error is returned if any element type not equal to NUMBER.
 func (j Json) BoolArray() ([]bool, error) {
@@ -326,7 +580,7 @@ func (j Json) BoolArray() ([]bool, error) {
 	}
 	ret := make([]bool, len(arr))
 	for i, e := range arr {
-		ret[i], err = e.Bool()
+		ret[i], err = j.returnj(e).Bool()
 		if err != nil {
 			return nil, err
 		}
@@ -343,7 +597,7 @@ func (j Json) StringArray() ([]string, error) {
 	}
 	ret := make([]string, len(arr))
 	for i, e := range arr {
-		ret[i], err = e.String()
+		ret[i], err = j.returnj(e).String()
 		if err != nil {
 			return nil, err
 		}
@@ -358,11 +612,14 @@ func (j Json) StringArray() ([]string, error) {
 // a NULL type Json returned with err if:
 //	- key type not supported. (neither number nor string)
 //	- json value type mismatch.
+// a *TypeMismatchError has the Path walked so far, the Error() of it is like
+// `users[3].address: type mismatch: expected OBJECT, found ARRAY`.
+// GetInt, GetString and the like add the keys to errors of the conversion too.
 func (j Json) Get(keys ...interface{}) Json {
-	for _, k := range keys {
-		if j.err != nil {
-			return j
-		}
+	if j.err != nil {
+		return j
+	}
+	for i, k := range keys {
 		switch t := k.(type) {
 		// reflect.ValueOf(t).Int() or Uint() ?
 		// without reflection here.
@@ -387,40 +644,139 @@ func (j Json) Get(keys ...interface{}) Json {
 		case uint64:
 			j = j.Element(int(t))
 		case string:
+			if t == Each {
+				v := j.EachOf(keys[i+1:]...)
+				if v.err != nil {
+					v.err = withPath(v.err, keys[:i]...)
+				}
+				return v
+			}
 			j = j.Member(t)
 		default:
-			return Json{err: errKeyType}
+			return Json{err: ErrKeyType}
+		}
+		if j.err != nil {
+			// the path walked so far, the value at it is not of the type expected by k.
+			j.err = withPath(j.err, keys[:i]...)
+			return j
 		}
 	}
 	return j
 }
 
+// at adds keys to the path of err returned by a conversion of j = Get(keys...),
+// errors of Get have the path already.
+func (j Json) at(err error, keys []interface{}) error {
+	if err == nil || j.err != nil {
+		return err
+	}
+	if _, ok := err.(*pathError); ok {
+		return atPath(err, keys)
+	}
+	return withPath(err, keys...)
+}
+
+// Lookup is like Get, but also reports whether the value specified by keys
+// is present in the document. It is false if the value is missing or Get fails,
+// and true for a null in the document.
+func (j Json) Lookup(keys ...interface{}) (Json, bool) {
+	v := j.Get(keys...)
+	return v, v.err == nil && !v.missing
+}
+
+// Has reports whether the value specified by keys is present in the document,
+// see Lookup.
+func (j Json) Has(keys ...interface{}) bool {
+	_, ok := j.Lookup(keys...)
+	return ok
+}
+
 // GetBool convert json value specified by keys to bool,
 // it is equal to Get(keys...).Bool()
 func (j Json) GetBool(keys ...interface{}) (bool, error) {
 	v := j.Get(keys...)
-	return v.Bool()
+	ret, err := v.Bool()
+	return ret, v.at(err, keys)
 }
 
 // GetString convert json value specified by keys to string,
 // it is equal to Get(keys...).String()
 func (j Json) GetString(keys ...interface{}) (string, error) {
 	v := j.Get(keys...)
-	return v.String()
+	ret, err := v.String()
+	return ret, v.at(err, keys)
 }
 
 // GetFloat  convert json value specified by keys to float64,
 // it is equal to Get(keys...).Float()
 func (j Json) GetFloat(keys ...interface{}) (float64, error) {
 	v := j.Get(keys...)
-	return v.Float()
+	ret, err := v.Float()
+	return ret, v.at(err, keys)
 }
 
 // GetInt convert json value specified by keys to int64,
 // it is equal to Get(keys...).Int()
 func (j Json) GetInt(keys ...interface{}) (int64, error) {
 	v := j.Get(keys...)
-	return v.Int()
+	ret, err := v.Int()
+	return ret, v.at(err, keys)
+}
+
+// GetBoolOr is like GetBool, but returns def if the value is missing, null
+// or can not be converted.
+func (j Json) GetBoolOr(def bool, keys ...interface{}) bool {
+	v := j.Get(keys...)
+	if v.null() { // before NullToZero and AllAsBool
+		return def
+	}
+	b, err := v.Bool()
+	if err != nil {
+		return def
+	}
+	return b
+}
+
+// GetStringOr is like GetString, but returns def if the value is missing, null
+// or can not be converted.
+func (j Json) GetStringOr(def string, keys ...interface{}) string {
+	v := j.Get(keys...)
+	if v.tp == NULL { // "" is a STRING even with EmptyStringToNull
+		return def
+	}
+	s, err := v.String()
+	if err != nil {
+		return def
+	}
+	return s
+}
+
+// GetFloatOr is like GetFloat, but returns def if the value is missing, null
+// or can not be converted.
+func (j Json) GetFloatOr(def float64, keys ...interface{}) float64 {
+	v := j.Get(keys...)
+	if v.null() {
+		return def
+	}
+	f, err := v.Float()
+	if err != nil {
+		return def
+	}
+	return f
+}
+
+// GetIntOr is like GetInt, but returns def if the value is missing, null
+// or can not be converted.
+func (j Json) GetIntOr(def int64, keys ...interface{}) int64 {
+	v := j.Get(keys...)
+	if v.null() {
+		return def
+	}
+	n, err := v.Int()
+	if err != nil {
+		return def
+	}
+	return n
 }
 
 // EachOf convert every elements specified by keys in json value to ARRAY.
@@ -435,7 +791,7 @@ func (j Json) EachOf(keys ...interface{}) Json {
 	} else if t == OBJECT {
 		arr, err = j.Values()
 	} else {
-		err = fmt.Errorf("type %s not supported EachOf()", j.Type())
+		err = fmt.Errorf("type %s %w", j.Type(), ErrEachOf)
 	}
 	if err != nil {
 		return Json{err: err}
@@ -444,9 +800,14 @@ func (j Json) EachOf(keys ...interface{}) Json {
 	ret.a = make([]Json, len(arr))
 	ret.tp = ARRAY
 	for i, e := range arr {
-		v := e.Get(keys...)
+		v := j.returnj(e).Get(keys...)
 		if v.err != nil {
-			return Json{err: v.err}
+			var k interface{} = i
+			if t == OBJECT {
+				j.load()
+				k = j.m[i].key(j.json5)
+			}
+			return Json{err: withPath(v.err, k)}
 		}
 		ret.a[i] = v
 	}
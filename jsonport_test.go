@@ -1,9 +1,19 @@
 package jsonport
 
 import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math"
 	"reflect"
 	"sort"
+	"strconv"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 )
 
 func TestJson_Number(t *testing.T) {
@@ -453,7 +463,7 @@ func TestJson_Other(t *testing.T) {
 	j, _ := Unmarshal([]byte(in))
 	// case key type not support
 	jj := j.Get(j)
-	if err := jj.Error(); err != errKeyType {
+	if err := jj.Error(); err != ErrKeyType {
 		t.Fatal(err)
 	}
 	if jj.Type() != INVALID {
@@ -511,6 +521,602 @@ func TestParseMemberNames(t *testing.T) {
 	}
 }
 
+func TestStrict(t *testing.T) {
+	strict := &Options{Strict: true}
+	for _, in := range []string{
+		`-`, `1-2e+`, `01`, `-01`, `1.`, `.5`, `1e`, `1e+`, `+1`,
+		"\v1", "[1,\f2]",
+		`"\'"`, `"\x41"`, `"\u12"`, "\"a\tb\"", "\"\xff\"",
+		`{"a": "\q"}`, `[1, 2, 01]`,
+	} {
+		if _, err := strict.Unmarshal([]byte(in)); err == nil {
+			t.Fatal(in)
+		}
+	}
+	for _, in := range []string{
+		`0`, `-0`, `-1.5e+10`, `1E-2`, `[ ]`, `{ }`, " \t\r\n1",
+		`"\"\\\/\b\f\n\r\t\u00e9"`, `"héllo"`,
+		`{"a": [1, {"b": null}], "c": true}`,
+	} {
+		if _, err := strict.Unmarshal([]byte(in)); err != nil {
+			t.Fatal(in, err)
+		}
+	}
+
+	// lenient by default
+	for _, in := range []string{`1-2e+`, `01`, "\v1", `"\'"`} {
+		if _, err := Unmarshal([]byte(in)); err != nil {
+			t.Fatal(in, err)
+		}
+	}
+	j, _ := Unmarshal([]byte(`"it\'s"`))
+	if s, err := j.String(); err != nil || s != "it's" {
+		t.Fatal(s, err)
+	}
+
+	// path fast path checks skipped values too
+	in := []byte(`{"a": 01, "b": 1}`)
+	if n, err := Unmarshal(in, "b"); err != nil {
+		t.Fatal(n, err)
+	}
+	if _, err := strict.Unmarshal(in, "b"); err == nil {
+		t.Fatal(nil)
+	}
+	in = []byte(`{"a": "\'", "b": 1}`)
+	if _, err := strict.Unmarshal(in, "b"); err == nil {
+		t.Fatal(nil)
+	}
+	if _, err := strict.DecodeFrom(strings.NewReader(`[1, 2, 3.]`)); err == nil {
+		t.Fatal(nil)
+	}
+}
+
+func TestSyntaxError(t *testing.T) {
+	in := "{\n  \"users\": [{\"id\": 1 x}]\n}"
+	_, err := Unmarshal([]byte(in))
+	var e *SyntaxError
+	if !errors.As(err, &e) {
+		t.Fatal(err)
+	}
+	if e.Offset != 23 || e.Line != 2 || e.Column != 22 {
+		t.Fatal(e.Offset, e.Line, e.Column)
+	}
+	if !reflect.DeepEqual(e.Path, []interface{}{"users", 0}) {
+		t.Fatal(e.Path)
+	}
+	if e.Snippet != "  \"users\": [{\"id\": 1 x}]\n                     ^" {
+		t.Fatal(e.Snippet)
+	}
+	if s := e.Error(); s != `syntax error at line 2, column 22 (offset 23) in users[0]: OBJECT: expect ',' or '}' found 'x'` {
+		t.Fatal(s)
+	}
+
+	// the same error from the path fast path
+	_, err = Unmarshal([]byte(in), "users", 0, "name")
+	if !errors.As(err, &e) || e.Offset != 23 {
+		t.Fatal(err)
+	}
+
+	// EOF errors wrap the sentinel errors
+	_, err = Unmarshal([]byte(`["abc`))
+	if !errors.As(err, &e) || e.Offset != 5 || !errors.Is(err, ErrStringEOF) {
+		t.Fatal(err)
+	}
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
+
+func TestDecoder(t *testing.T) {
+	in := "{\"a\": 1}{\"a\": [2]} 123 \"x\"\ntrue\nnull-4.5e1[]  "
+	for _, r := range []io.Reader{strings.NewReader(in), iotest.OneByteReader(strings.NewReader(in))} {
+		d := NewDecoder(r)
+		d.Buffer(4, 16)
+		var types []Type
+		var offsets []int64
+		for d.More() {
+			j, err := d.Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			types = append(types, j.Type())
+			offsets = append(offsets, d.InputOffset())
+			if len(types) == 2 {
+				if n, _ := j.GetInt("a", 0); n != 2 {
+					t.Fatal(n)
+				}
+			}
+			if len(types) == 3 {
+				if n, _ := j.Int(); n != 123 {
+					t.Fatal(n)
+				}
+			}
+			if len(types) == 7 {
+				if f, _ := j.Float(); f != -45 {
+					t.Fatal(f)
+				}
+			}
+		}
+		if !reflect.DeepEqual(types, []Type{OBJECT, OBJECT, NUMBER, STRING, BOOL, NULL, NUMBER, ARRAY}) {
+			t.Fatal(types)
+		}
+		if !reflect.DeepEqual(offsets, []int64{8, 18, 22, 26, 31, 36, 42, 44}) {
+			t.Fatal(offsets)
+		}
+		if _, err := d.Decode(); err != io.EOF {
+			t.Fatal(err)
+		}
+	}
+
+	// case value too long
+	d := NewDecoder(strings.NewReader(`[1, 2, 3, 4, 5, 6, 7, 8, 9]`))
+	d.Buffer(4, 16)
+	if _, err := d.Decode(); err != ErrTooLong {
+		t.Fatal(err)
+	}
+
+	// case syntax error located in the whole input
+	d = NewDecoder(strings.NewReader("1\n2\n{\"a\": x}"))
+	for i := 0; i < 2; i++ {
+		if _, err := d.Decode(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := d.Decode()
+	var e *SyntaxError
+	if !errors.As(err, &e) || e.Offset != 10 || e.Line != 3 || e.Column != 7 {
+		t.Fatal(err)
+	}
+
+	// case incomplete value
+	d = NewDecoder(strings.NewReader(`{"a": [1, 2`))
+	if _, err := d.Decode(); !errors.Is(err, ErrArrayEOF) {
+		t.Fatal(err)
+	}
+
+	// case read error
+	errRead := errors.New("read error")
+	d = NewDecoder(io.MultiReader(strings.NewReader(`[1, `), errReader{errRead}))
+	if _, err := d.Decode(); err != errRead {
+		t.Fatal(err)
+	}
+	if _, err := DecodeFrom(errReader{errRead}); err != errRead {
+		t.Fatal(err)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	deep := strings.Repeat("[", 100) + strings.Repeat("]", 100)
+	cases := []struct {
+		opt   Options
+		in    string
+		keys  []interface{}
+		limit string
+	}{
+		{Options{MaxDepth: 99}, deep, nil, "MaxDepth"},
+		{Options{MaxDepth: 99}, `{"a": 1, "b": ` + deep + `}`, []interface{}{"a"}, ""},
+		{Options{MaxDepth: 99}, `{"b": ` + deep + `, "a": 1}`, []interface{}{"a"}, "MaxDepth"},
+		{Options{MaxDepth: 100}, deep, nil, ""},
+		{Options{MaxBytes: 8}, `[1, 2, 3]`, nil, "MaxBytes"},
+		{Options{MaxMembers: 2}, `{"a": 1, "b": 2, "c": 3}`, nil, "MaxMembers"},
+		{Options{MaxMembers: 2}, `{"a": 1, "b": 2, "c": 3}`, []interface{}{"c"}, "MaxMembers"},
+		{Options{MaxMembers: 3}, `{"a": 1, "b": 2, "c": 3}`, nil, ""},
+		{Options{MaxElements: 2}, `[[1, 2, 3]]`, nil, "MaxElements"},
+		{Options{MaxElements: 2}, `[[1, 2, 3], 4]`, []interface{}{1}, "MaxElements"},
+		{Options{MaxStringLen: 3}, `["abc", "abcd"]`, nil, "MaxStringLen"},
+		{Options{MaxStringLen: 3}, `{"abcd": 1}`, nil, "MaxStringLen"},
+		{Options{MaxStringLen: 3}, `{"a": "toolong", "b": 1}`, []interface{}{"b"}, "MaxStringLen"},
+		{Options{MaxStringLen: 3, Lazy: true}, `{"a": "toolong", "b": 1}`, nil, "MaxStringLen"},
+	}
+	for _, c := range cases {
+		_, err := c.opt.Unmarshal([]byte(c.in), c.keys...)
+		var e *LimitError
+		if c.limit == "" {
+			if err != nil {
+				t.Fatal(c.in, err)
+			}
+		} else if !errors.As(err, &e) || e.Limit != c.limit {
+			t.Fatal(c.in, err)
+		}
+	}
+
+	for _, strict := range []bool{false, true} {
+		opt := Options{MaxStringLen: 3, Strict: strict}
+		_, err := opt.UnmarshalPaths([]byte(`{"a": "toolong", "b": 1}`), []interface{}{"b"})
+		if e := (*LimitError)(nil); !errors.As(err, &e) || e.Limit != "MaxStringLen" {
+			t.Fatal(strict, err)
+		}
+	}
+
+	opt := Options{MaxBytes: 8}
+	d := opt.NewDecoder(strings.NewReader(`[1, 2] [1, 2, 3]`))
+	if _, err := d.Decode(); err != nil {
+		t.Fatal(err)
+	}
+	var e *LimitError
+	if _, err := d.Decode(); !errors.As(err, &e) || e.Offset != 15 {
+		t.Fatal(err)
+	}
+
+	// case context
+	ctx, cancel := context.WithCancel(context.Background())
+	in := []byte("[" + strings.Repeat("1,", 10000) + "1]")
+	if _, err := UnmarshalContext(ctx, in); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if _, err := UnmarshalContext(ctx, in); err != context.Canceled {
+		t.Fatal(err)
+	}
+	if _, err := UnmarshalContext(ctx, in, 9999); err != context.Canceled {
+		t.Fatal(err)
+	}
+	// canceled while parsing
+	p := newParser(nil, in)
+	p.ctx = ctx
+	if _, err := p.unmarshal(); err != context.Canceled {
+		t.Fatal(err)
+	}
+}
+
+func TestUnmarshalPaths(t *testing.T) {
+	in := []byte(`{
+		"id": 1,
+		"user": {"name": "Tom", "tags": ["a", "b"], "age": 20},
+		"items": [{"id": 10}, {"id": 11}],
+		"status": "ok"
+	}`)
+	paths := [][]interface{}{
+		{"status"},
+		{"user", "name"},
+		{"user", "tags", 1},
+		{"items", 1, "id"},
+		{"items", 5},
+		{"missing", "x"},
+		{"user", "tags"},
+		{"user", "tags", 0},
+		{"id", "x"},
+		{"user", ParseMemberNamesOnly},
+		{"id"},
+	}
+	ret, err := UnmarshalPaths(in, paths...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ret) != len(paths) {
+		t.Fatal(len(ret))
+	}
+	for i, path := range paths[:8] {
+		j, err := Unmarshal(in, path...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ret[i].Type() != j.Type() || !reflect.DeepEqual(ret[i].b, j.b) {
+			t.Fatal(path, ret[i].Type(), j.Type())
+		}
+	}
+	if arr, _ := ret[6].StringArray(); !reflect.DeepEqual(arr, []string{"a", "b"}) {
+		t.Fatal(arr)
+	}
+	if ret[8].Error() == nil {
+		t.Fatal(ret[8].Type())
+	}
+	if keys, _ := ret[9].Keys(); !reflect.DeepEqual(keys, []string{"name", "tags", "age"}) || !ret[9].Member("age").IsNull() {
+		t.Fatal(keys)
+	}
+	if n, err := ret[10].Int(); n != 1 || err != nil {
+		t.Fatal(n, err)
+	}
+
+	// the whole document and paths in it
+	ret, err = UnmarshalPaths(in, []interface{}{}, []interface{}{"items", 0, "id"}, []interface{}{"user", ParseMemberNamesOnly})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ret[0].IsObject() {
+		t.Fatal(ret[0].Type())
+	}
+	if n, _ := ret[1].Int(); n != 10 {
+		t.Fatal(n)
+	}
+	if n, _ := ret[2].Len(); n != 3 {
+		t.Fatal(n)
+	}
+
+	// paths under a path requested as well are the same as without it
+	for _, path := range [][]interface{}{{"items", 2, "id"}, {"items", 0, "id", "x"}, {"user", "x", "y"}, {"items", Each, "x", 0}} {
+		alone, err := UnmarshalPaths(in, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ret, err := UnmarshalPaths(in, path, path[:1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		a, b := alone[0], ret[0]
+		if a.Type() != b.Type() || a.IsMissing() != b.IsMissing() || fmt.Sprint(a.Error()) != fmt.Sprint(b.Error()) {
+			t.Fatal(path, a.Type(), a.Error(), b.Type(), b.Error())
+		}
+	}
+	if ret, _ := UnmarshalPaths(in, []interface{}{"items", 2, "id"}, []interface{}{"items"}); !ret[0].IsMissing() {
+		t.Fatal(ret[0].Type(), ret[0].Error())
+	}
+	ret, _ = UnmarshalPaths(in, []interface{}{"items", 0, "id", "x"}, []interface{}{"items"})
+	if err := ret[0].Error(); err == nil || err.Error() != "items[0].id: type mismatch: expected OBJECT, found NUMBER" {
+		t.Fatal(err)
+	}
+
+	// case skipped values are still checked
+	if _, err := UnmarshalPaths([]byte(`{"a": 1, "b": [1, }`), []interface{}{"a"}); err == nil {
+		t.Fatal(nil)
+	}
+	// case key type error
+	if _, err := UnmarshalPaths(in, []interface{}{1.5}); err != ErrKeyType {
+		t.Fatal(err)
+	}
+}
+
+// equalJson reports whether a and b are the same JSON value.
+func equalJson(a, b Json) bool {
+	if a.Type() != b.Type() || (a.Error() == nil) != (b.Error() == nil) {
+		return false
+	}
+	switch a.Type() {
+	case OBJECT:
+		ka, _ := a.Keys()
+		kb, _ := b.Keys()
+		if !reflect.DeepEqual(ka, kb) {
+			return false
+		}
+		va, _ := a.Values()
+		vb, _ := b.Values()
+		for i := range va {
+			if !equalJson(va[i], vb[i]) {
+				return false
+			}
+		}
+	case ARRAY:
+		aa, _ := a.Array()
+		ab, _ := b.Array()
+		if len(aa) != len(ab) {
+			return false
+		}
+		for i := range aa {
+			if !equalJson(aa[i], ab[i]) {
+				return false
+			}
+		}
+	case STRING, NUMBER:
+		return string(a.b) == string(b.b)
+	case BOOL:
+		return a.t == b.t
+	}
+	return true
+}
+
+func TestEach(t *testing.T) {
+	in := []byte(`{
+		"users": [
+			{"id": 1, "name": "Tom", "tags": ["a"]},
+			{"id": 2, "name": "Peter", "tags": []},
+			{"id": 3, "tags": ["b", "c"]}
+		],
+		"groups": {"g1": {"size": 1}, "g2": {"size": 2}},
+		"n": 1
+	}`)
+
+	j, err := Unmarshal(in, "users", Each, "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := j.Len(); !j.IsArray() || n != 3 {
+		t.Fatal(j.Type(), n)
+	}
+	if s, _ := j.GetString(1); s != "Peter" {
+		t.Fatal(s)
+	}
+	if !j.Element(2).IsNull() {
+		t.Fatal(j.Element(2).Type())
+	}
+
+	j, err = Unmarshal(in, "groups", Each, "size")
+	if arr, _ := j.IntArray(); !reflect.DeepEqual(arr, []int64{1, 2}) {
+		t.Fatal(arr, err)
+	}
+	j, err = Unmarshal(in, "users", Each, "tags", Each)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := j.GetString(2, 1); s != "c" {
+		t.Fatal(s)
+	}
+	if _, err := Unmarshal(in, "n", Each); err == nil {
+		t.Fatal(nil)
+	}
+	if _, err := Unmarshal(in, "users", Each, "tags", 0, "x"); err == nil {
+		t.Fatal(nil)
+	}
+
+	// the same with Get, UnmarshalPaths and EachOf
+	full, _ := Unmarshal(in)
+	paths := [][]interface{}{
+		{"users", Each, "name"},
+		{"users", Each, "tags", Each},
+		{"groups", Each, "size"},
+		{"users", 0, "id"},
+		{"users", Each},
+		{"n", Each},
+	}
+	ret, err := UnmarshalPaths(in, paths...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, path := range paths[:5] {
+		a, err := Unmarshal(in, path...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b := full.Get(path...)
+		if !equalJson(a, b) || !equalJson(a, ret[i]) {
+			t.Fatal(path)
+		}
+	}
+	if ret[5].Error() == nil || full.Get("n", Each).Error() == nil {
+		t.Fatal(ret[5].Type())
+	}
+	names, _ := full.Get("users").EachOf("name").StringArray()
+	if s, _ := full.Get("users", Each, "name").StringArray(); !reflect.DeepEqual(s, names) {
+		t.Fatal(s, names)
+	}
+}
+
+func TestLazy(t *testing.T) {
+	lazy := &Options{Lazy: true}
+	j, err := lazy.Unmarshal(mediumFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j.lz == nil || j.m != nil {
+		t.Fatal("not lazy")
+	}
+	full, _ := Unmarshal(mediumFixture)
+	if !equalJson(j, full) {
+		t.Fatal("not equal")
+	}
+
+	person := j.Member("person")
+	if !person.IsObject() || person.lz == nil {
+		t.Fatal(person.Type())
+	}
+	if s, _ := j.GetString("person", "gravatar", "avatars", 0, "type"); s != "thumbnail" {
+		t.Fatal(s)
+	}
+	// parsed once and cached
+	if person.lz.m == nil || j.Member("person").lz != person.lz {
+		t.Fatal("not cached")
+	}
+	if n, _ := j.Get("person", "gravatar", "urls").Len(); n != 0 {
+		t.Fatal(n)
+	}
+
+	// syntax errors are still found at first
+	if _, err := lazy.Unmarshal([]byte(`{"a": [1, 2}`)); err == nil {
+		t.Fatal(nil)
+	}
+	// and so are the limits
+	limited := &Options{Lazy: true, MaxDepth: 2}
+	j, err = limited.Unmarshal([]byte(`{"a": [[1]]}`))
+	if err == nil {
+		t.Fatal(nil)
+	}
+	j, err = lazy.Unmarshal([]byte(`[{"a": [1]}]`), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := j.GetInt("a", 0); n != 1 || err != nil {
+		t.Fatal(n, err)
+	}
+}
+
+func TestRaw(t *testing.T) {
+	in := []byte(`{"a": {"b": [1, 2.50, "x\ty"]}, "c": true, "d": null, "e": ""}`)
+	j, _ := Unmarshal(in)
+	cases := []struct {
+		keys []interface{}
+		raw  string
+	}{
+		{nil, string(in)},
+		{[]interface{}{"a"}, `{"b": [1, 2.50, "x\ty"]}`},
+		{[]interface{}{"a", "b"}, `[1, 2.50, "x\ty"]`},
+		{[]interface{}{"a", "b", 1}, `2.50`},
+		{[]interface{}{"a", "b", 2}, `"x\ty"`},
+		{[]interface{}{"c"}, `true`},
+		{[]interface{}{"d"}, `null`},
+		{[]interface{}{"e"}, `""`},
+	}
+	lazy, _ := (&Options{Lazy: true}).Unmarshal(in)
+	for _, c := range cases {
+		if raw := j.Get(c.keys...).Raw(); string(raw) != c.raw {
+			t.Fatal(c.keys, string(raw))
+		}
+		if raw := lazy.Get(c.keys...).Raw(); string(raw) != c.raw {
+			t.Fatal(c.keys, string(raw))
+		}
+		if len(c.keys) == 0 {
+			continue
+		}
+		jj, err := Unmarshal(in, c.keys...)
+		if raw := jj.Raw(); string(raw) != c.raw || err != nil {
+			t.Fatal(c.keys, string(raw), err)
+		}
+	}
+	if s, _ := j.GetString("a", "b", 2); s != "x\ty" {
+		t.Fatal(s)
+	}
+	if raw := j.Get("x").Raw(); raw != nil {
+		t.Fatal(string(raw))
+	}
+	if raw := j.EachOf().Raw(); raw != nil {
+		t.Fatal(string(raw))
+	}
+}
+
+func TestClone(t *testing.T) {
+	const in = `{"name": "Tom", "tags": ["a", "b\u00e9"], "n": 1.5, "o": {"k": "v"}}`
+	check := func(j Json) {
+		if s, _ := j.GetString("name"); s != "Tom" {
+			t.Fatal(s)
+		}
+		if s, _ := j.Get("tags").StringArray(); !reflect.DeepEqual(s, []string{"a", "bé"}) {
+			t.Fatal(s)
+		}
+		if f, _ := j.GetFloat("n"); f != 1.5 {
+			t.Fatal(f)
+		}
+		if keys, _ := j.Keys(); !reflect.DeepEqual(keys, []string{"name", "tags", "n", "o"}) {
+			t.Fatal(keys)
+		}
+		if s, _ := j.GetString("o", "k"); s != "v" {
+			t.Fatal(s)
+		}
+		if string(j.Raw()) != in {
+			t.Fatal(string(j.Raw()))
+		}
+	}
+	clobber := func(b []byte) {
+		for i := range b {
+			b[i] = 'x'
+		}
+	}
+
+	for _, opt := range []*Options{nil, {Lazy: true}} {
+		buf := []byte(in)
+		j, err := opt.Unmarshal(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		j.Keys() // cache the member names
+		c := j.Clone()
+		clobber(buf)
+		check(c)
+
+		// a value in the middle of the input
+		buf = []byte(in)
+		j, _ = opt.Unmarshal(buf)
+		c = j.Get("tags").Clone()
+		clobber(buf)
+		if s, _ := c.StringArray(); !reflect.DeepEqual(s, []string{"a", "bé"}) {
+			t.Fatal(s)
+		}
+	}
+
+	buf := []byte(in)
+	j, _ := (&Options{Copy: true}).Unmarshal(buf)
+	clobber(buf)
+	check(j)
+}
+
 func BenchmarkUnmarshalSmall(b *testing.B) {
 	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
@@ -525,6 +1131,1122 @@ func BenchmarkUnmarshalMedium(b *testing.B) {
 	}
 }
 
+func BenchmarkUnmarshalLazyMedium(b *testing.B) {
+	opt := &Options{Lazy: true}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		j, _ := opt.Unmarshal(mediumFixture)
+		j.GetInt("person", "github", "followers")
+	}
+}
+
 var smallFixture = []byte(`{"st":1,"sid":486,"tt":"active","gr":0,"uuid":"de305d54-75b4-431b-adb2-eb6b9e546014","ip":"127.0.0.1","ua":"user_agent","tz":-6,"v":1}`)
 
 var mediumFixture = []byte(`{"person":{"id":"d50887ca-a6ce-4e59-b89f-14f0b5d03b03","name":{"fullName":"LeonidBugaev","givenName":"Leonid","familyName":"Bugaev"},"email":"leonsbox@gmail.com","gender":"male","location":"SaintPetersburg,SaintPetersburg,RU","geo":{"city":"SaintPetersburg","state":"SaintPetersburg","country":"Russia","lat":59.9342802,"lng":30.3350986},"bio":"SeniorengineeratGranify.com","site":"http://flickfaver.com","avatar":"https://d1ts43dypk8bqh.cloudfront.net/v1/avatars/d50887ca-a6ce-4e59-b89f-14f0b5d03b03","employment":{"name":"www.latera.ru","title":"SoftwareEngineer","domain":"gmail.com"},"facebook":{"handle":"leonid.bugaev"},"github":{"handle":"buger","id":14009,"avatar":"https://avatars.githubusercontent.com/u/14009?v=3","company":"Granify","blog":"http://leonsbox.com","followers":95,"following":10},"twitter":{"handle":"flickfaver","id":77004410,"bio":null,"followers":2,"following":1,"statuses":5,"favorites":0,"location":"","site":"http://flickfaver.com","avatar":null},"linkedin":{"handle":"in/leonidbugaev"},"googleplus":{"handle":null},"angellist":{"handle":"leonid-bugaev","id":61541,"bio":"SeniorengineeratGranify.com","blog":"http://buger.github.com","site":"http://buger.github.com","followers":41,"avatar":"https://d1qb2nb5cznatu.cloudfront.net/users/61541-medium_jpg?1405474390"},"klout":{"handle":null,"score":null},"foursquare":{"handle":null},"aboutme":{"handle":"leonid.bugaev","bio":null,"avatar":null},"gravatar":{"handle":"buger","urls":[],"avatar":"http://1.gravatar.com/avatar/f7c8edd577d13b8930d5522f28123510","avatars":[{"url":"http://1.gravatar.com/avatar/f7c8edd577d13b8930d5522f28123510","type":"thumbnail"}]},"fuzzy":false},"company":null}`)
+
+func BenchmarkUnmarshalPathsMedium(b *testing.B) {
+	paths := [][]interface{}{
+		{"person", "name", "fullName"},
+		{"person", "github", "followers"},
+		{"person", "gravatar", "avatars", 0, "url"},
+		{"company"},
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		UnmarshalPaths(mediumFixture, paths...)
+	}
+}
+
+func TestDuplicateKeys(t *testing.T) {
+	const in = `{"a": 1, "b": {"c": 2}, "a": 3, "b": {"c": 4, "d": 5}}`
+	tests := []struct {
+		policy DuplicatePolicy
+		keys   []string
+		a, c   int64
+	}{
+		{DuplicateKeepAll, []string{"a", "b", "a", "b"}, 1, 2},
+		{DuplicateFirstWins, []string{"a", "b"}, 1, 2},
+		{DuplicateLastWins, []string{"a", "b"}, 3, 4},
+	}
+	for _, tc := range tests {
+		for _, lazy := range []bool{false, true} {
+			opt := &Options{Duplicates: tc.policy, Lazy: lazy}
+			j, err := opt.Unmarshal([]byte(in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if keys, _ := j.Keys(); !reflect.DeepEqual(keys, tc.keys) {
+				t.Fatal(tc.policy, keys)
+			}
+			if values, _ := j.Values(); len(values) != len(tc.keys) {
+				t.Fatal(tc.policy, values)
+			}
+			if n, _ := j.GetInt("a"); n != tc.a {
+				t.Fatal(tc.policy, n)
+			}
+			if n, _ := j.GetInt("b", "c"); n != tc.c {
+				t.Fatal(tc.policy, n)
+			}
+
+			// the fast path
+			a, err := opt.Unmarshal([]byte(in), "a")
+			if n, _ := a.Int(); err != nil || n != tc.a {
+				t.Fatal(tc.policy, n, err)
+			}
+			c, err := opt.Unmarshal([]byte(in), "b", "c")
+			if n, _ := c.Int(); err != nil || n != tc.c {
+				t.Fatal(tc.policy, n, err)
+			}
+			names, err := opt.Unmarshal([]byte(in), ParseMemberNamesOnly)
+			if keys, _ := names.Keys(); err != nil || !reflect.DeepEqual(keys, tc.keys) {
+				t.Fatal(tc.policy, keys, err)
+			}
+
+			ret, err := opt.UnmarshalPaths([]byte(in), []interface{}{"a"}, []interface{}{"b", "c"}, []interface{}{ParseMemberNamesOnly})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n, _ := ret[0].Int(); n != tc.a {
+				t.Fatal(tc.policy, n)
+			}
+			if n, _ := ret[1].Int(); n != tc.c {
+				t.Fatal(tc.policy, n)
+			}
+			if keys, _ := ret[2].Keys(); !reflect.DeepEqual(keys, tc.keys) {
+				t.Fatal(tc.policy, keys)
+			}
+		}
+	}
+
+	const each = `[{"a": 1, "a": 2}, {"a": 3}]`
+	for policy, want := range map[DuplicatePolicy][]int64{DuplicateFirstWins: {1, 3}, DuplicateLastWins: {2, 3}} {
+		opt := &Options{Duplicates: policy}
+		j, _ := opt.Unmarshal([]byte(each), Each, "a")
+		if a, _ := j.IntArray(); !reflect.DeepEqual(a, want) {
+			t.Fatal(policy, a)
+		}
+		ret, _ := opt.UnmarshalPaths([]byte(`{"x": 1, "x": 2, "y": 3}`), []interface{}{Each})
+		if a, _ := ret[0].IntArray(); !reflect.DeepEqual(a, []int64{want[0], 3}) {
+			t.Fatal(policy, a)
+		}
+	}
+
+	opt := &Options{Duplicates: DuplicateReject}
+	for _, keys := range [][]interface{}{nil, {"a"}, {"b", "c"}, {ParseMemberNamesOnly}, {Each}} {
+		_, err := opt.Unmarshal([]byte(in), keys...)
+		var e *SyntaxError
+		if !errors.Is(err, ErrDuplicateKey) || !errors.As(err, &e) || e.Offset != 24 {
+			t.Fatal(keys, err)
+		}
+	}
+	if _, err := opt.UnmarshalPaths([]byte(in), []interface{}{"a"}); !errors.Is(err, ErrDuplicateKey) {
+		t.Fatal(err)
+	}
+	if _, err := (&Options{Duplicates: DuplicateReject, Lazy: true}).Unmarshal([]byte(`[` + in + `]`)); !errors.Is(err, ErrDuplicateKey) {
+		t.Fatal(err)
+	}
+
+	// duplicates count for MaxMembers
+	many := `{"a": 0` + strings.Repeat(`, "a": 1`, 20) + `}`
+	for _, policy := range []DuplicatePolicy{DuplicateKeepAll, DuplicateFirstWins, DuplicateLastWins} {
+		opt := &Options{Duplicates: policy, MaxMembers: 10}
+		for _, keys := range [][]interface{}{nil, {"b"}, {ParseMemberNamesOnly}} {
+			var e *LimitError
+			if _, err := opt.Unmarshal([]byte(many), keys...); !errors.As(err, &e) || e.Limit != "MaxMembers" {
+				t.Fatal(policy, keys, err)
+			}
+		}
+	}
+}
+
+func TestMemberIndex(t *testing.T) {
+	var buf strings.Builder
+	buf.WriteString(`{`)
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&buf, `"k%d": %d, `, i, i)
+	}
+	buf.WriteString(`"k7": -1, "a\"b": "x"}`)
+
+	for _, opt := range []*Options{nil, {Lazy: true}} {
+		j, err := opt.Unmarshal([]byte(buf.String()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, j := range []Json{j, j.Clone()} {
+			for i := 0; i < 1000; i++ {
+				if n, _ := j.GetInt(fmt.Sprintf("k%d", i)); n != int64(i) {
+					t.Fatal(i, n)
+				}
+			}
+			if s, _ := j.GetString(`a"b`); s != "x" {
+				t.Fatal(s)
+			}
+			if tp := j.Member("k1000").Type(); tp != NULL {
+				t.Fatal(tp)
+			}
+			if n, _ := j.Len(); n != 1002 {
+				t.Fatal(n)
+			}
+		}
+	}
+}
+
+func BenchmarkMemberLarge(b *testing.B) {
+	var buf strings.Builder
+	buf.WriteString(`{`)
+	for i := 0; i < 10000; i++ {
+		fmt.Fprintf(&buf, `"k%d": %d, `, i, i)
+	}
+	buf.WriteString(`"end": 0}`)
+	j, _ := Unmarshal([]byte(buf.String()))
+	keys := make([]string, 100)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("k%d", i*100)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		j.Member(keys[i%len(keys)])
+	}
+}
+
+func TestJSON5(t *testing.T) {
+	const in = `// config
+{
+	name: 'Tom "T"', /* inline */ $id_2: 0x1F,
+	'list': [1, +2, .5, 5., -0XFF, Infinity, -Infinity, NaN,], // trailing comma
+	"s": 'a\'b\x41\
+c',
+	nested: {a: {b: 1,},},
+}
+`
+	check := func(j Json) {
+		if s, _ := j.GetString("name"); s != `Tom "T"` {
+			t.Fatal(s)
+		}
+		if n, _ := j.GetInt("$id_2"); n != 31 {
+			t.Fatal(n)
+		}
+		a, err := j.Get("list").FloatArray()
+		if err != nil || len(a) != 8 {
+			t.Fatal(a, err)
+		}
+		want := []float64{1, 2, .5, 5, -255, math.Inf(1), math.Inf(-1)}
+		if !reflect.DeepEqual(a[:7], want) || !math.IsNaN(a[7]) {
+			t.Fatal(a)
+		}
+		if n, _ := j.Get("list", 4).Int(); n != -255 {
+			t.Fatal(n)
+		}
+		if s, _ := j.GetString("s"); s != "a'bAc" {
+			t.Fatal(s)
+		}
+		if n, _ := j.GetInt("nested", "a", "b"); n != 1 {
+			t.Fatal(n)
+		}
+		if keys, _ := j.Keys(); !reflect.DeepEqual(keys, []string{"name", "$id_2", "list", "s", "nested"}) {
+			t.Fatal(keys)
+		}
+	}
+	for _, opt := range []*Options{{JSON5: true}, {JSON5: true, Strict: true}, {JSON5: true, Lazy: true}} {
+		j, err := opt.Unmarshal([]byte(in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(j)
+		if n, err := opt.Unmarshal([]byte(in), "nested", "a", "b"); err != nil || n.Type() != NUMBER {
+			t.Fatal(n, err)
+		}
+		if a, err := opt.Unmarshal([]byte(in), "list", Each); err != nil || len(a.a) != 8 {
+			t.Fatal(a, err)
+		}
+		ret, err := opt.UnmarshalPaths([]byte(in), []interface{}{"s"}, []interface{}{"nested", "a", "b"})
+		if s, _ := ret[0].String(); err != nil || s != "a'bAc" {
+			t.Fatal(s, err)
+		}
+	}
+
+	d := (&Options{JSON5: true}).NewDecoder(iotest.OneByteReader(strings.NewReader(in + "/* next */ 'x' // end")))
+	j, err := d.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(j)
+	if j, err = d.Decode(); err != nil || j.b[0] != '\'' {
+		t.Fatal(j, err)
+	}
+	if _, err := d.Decode(); err != io.EOF {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{`{a: 1}`, `[1,]`, `{"a": 1,}`, `'a'`, `0x1F`, `+1`, `.5`, `NaN`, `1 // c`, `/* c */ 1`} {
+		if _, err := Unmarshal([]byte(s)); err == nil {
+			t.Fatal(s)
+		}
+		if _, err := (&Options{JSON5: true}).Unmarshal([]byte(s)); err != nil {
+			t.Fatal(s, err)
+		}
+	}
+	for _, s := range []string{`[1,,]`, `{,}`, `[1 /* c`, `[1 / 2]`, `0x`, `Infinit`, `{a b: 1}`, `'a`} {
+		if _, err := (&Options{JSON5: true}).Unmarshal([]byte(s)); err == nil {
+			t.Fatal(s)
+		}
+	}
+	if _, err := (&Options{JSON5: true, Strict: true}).Unmarshal([]byte(`01.2.3`)); err == nil {
+		t.Fatal("invalid number")
+	}
+	d = (&Options{Strict: true, JSON5: true}).NewDecoder(iotest.OneByteReader(strings.NewReader(`[1.5e3] 1.5e3 2`)))
+	for _, want := range []Type{ARRAY, NUMBER, NUMBER} {
+		if j, err := d.Decode(); err != nil || j.Type() != want {
+			t.Fatal(j, err)
+		}
+	}
+
+	// comments in a row are skipped without recursion
+	many := strings.Repeat("/**/", 5000) + "1" + strings.Repeat("//\n", 1000)
+	if j, err := (&Options{JSON5: true, MaxDepth: 10}).Unmarshal([]byte(many)); err != nil || j.Type() != NUMBER {
+		t.Fatal(j, err)
+	}
+}
+
+func TestBigNumber(t *testing.T) {
+	j, err := Unmarshal([]byte(`{"id": 123456789012345678901234567890, "amount": 1234567890.123456789012,
+		"e": 1.5e3, "ids": [1, 18446744073709551616, -2e2], "f": 1.5, "s": "100"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := j.Get("id").BigInt(); err != nil || n.String() != "123456789012345678901234567890" {
+		t.Fatal(n, err)
+	}
+	if n, err := j.Get("e").BigInt(); err != nil || n.Int64() != 1500 {
+		t.Fatal(n, err)
+	}
+	if _, err := j.Get("f").BigInt(); !errors.Is(err, ErrNotInteger) {
+		t.Fatal(err)
+	}
+	if _, err := j.Get("s").BigInt(); err == nil {
+		t.Fatal("type mismatch")
+	}
+	// valid integers with exponents too large for big.Rat are out of range
+	huge := Number("1e100000000")
+	var re *RangeError
+	if _, err := huge.BigInt(); !huge.IsInteger() || !errors.As(err, &re) || re.Type != "big.Int" {
+		t.Fatal(err)
+	}
+	if _, err := (Json{tp: NUMBER, b: []byte(huge)}).Int32(); !errors.As(err, &re) || re.Type != "int32" {
+		t.Fatal(err)
+	}
+	if _, err := Number("1e").BigInt(); !errors.Is(err, strconv.ErrSyntax) {
+		t.Fatal(err)
+	}
+	if r, err := j.Get("amount").Rat(); err != nil || r.FloatString(12) != "1234567890.123456789012" {
+		t.Fatal(r, err)
+	}
+	if f, err := j.Get("amount").BigFloat(); err != nil || f.Text('f', 12) != "1234567890.123456789012" {
+		t.Fatal(f, err)
+	}
+	if a, err := j.Get("ids").BigIntArray(); err != nil || len(a) != 3 || a[1].String() != "18446744073709551616" || a[2].Int64() != -200 {
+		t.Fatal(a, err)
+	}
+	if a, err := j.Get("ids").RatArray(); err != nil || len(a) != 3 {
+		t.Fatal(a, err)
+	}
+	if a, err := j.Get("ids").BigFloatArray(); err != nil || len(a) != 3 {
+		t.Fatal(a, err)
+	}
+	if j.Get("f").IsInteger() || !j.Get("e").IsInteger() || j.Get("s").IsInteger() {
+		t.Fatal("IsInteger")
+	}
+	v := j.Get("s")
+	v.StringAsNumber()
+	if n, err := v.BigInt(); err != nil || n.Int64() != 100 {
+		t.Fatal(n, err)
+	}
+
+	for s, want := range map[Number]bool{
+		"1": true, "-0": true, "1.0": true, "1.55e1": false, "1.50e1": true, "10e-1": true,
+		"15e-1": false, "0.0e-5": true, "1e400": true, "0x1F": true, "1.5": false,
+		"Infinity": false, "NaN": false, "1.2.3": false, "": false, ".": false,
+	} {
+		if s.IsInteger() != want {
+			t.Fatal(s, want)
+		}
+	}
+	if f, err := Number("-Infinity").BigFloat(); err != nil || !f.IsInf() || f.Sign() >= 0 {
+		t.Fatal(f, err)
+	}
+	if n, err := Number("-0x1F").BigInt(); err != nil || n.Int64() != -31 {
+		t.Fatal(n, err)
+	}
+	if _, err := Number("NaN").BigFloat(); err == nil {
+		t.Fatal("NaN")
+	}
+}
+
+func TestNarrowInt(t *testing.T) {
+	j, err := Unmarshal([]byte(`{"big": 18446744073709551615, "neg": -1, "i32": -2147483648,
+		"over": 2147483648, "frac": 1.5, "e": 2e2, "u8": [0, 255, 256], "s": "12"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u, err := j.GetUint("big"); err != nil || u != math.MaxUint64 {
+		t.Fatal(u, err)
+	}
+	var re *RangeError
+	if _, err := j.GetUint("neg"); !errors.As(err, &re) || re.Type != "uint64" || re.Num != "-1" || !errors.Is(err, strconv.ErrRange) {
+		t.Fatal(err)
+	}
+	if n, err := j.GetInt32("i32"); err != nil || n != math.MinInt32 {
+		t.Fatal(n, err)
+	}
+	if _, err := j.GetInt64("big"); !errors.As(err, &re) || re.Type != "int64" {
+		t.Fatal(err)
+	}
+	if n, err := j.GetInt64("e"); err != nil || n != 200 {
+		t.Fatal(n, err)
+	}
+	v, _ := Unmarshal([]byte(`[-9223372036854775808, 9223372036854775808]`))
+	if n, _ := v.GetInt(1); n != math.MinInt64 {
+		t.Fatal(n) // Int wraps
+	}
+	if _, err := v.Int64Array(); !errors.As(err, &re) || re.Num != "9223372036854775808" {
+		t.Fatal(err)
+	}
+	if n, err := v.GetInt64(0); err != nil || n != math.MinInt64 {
+		t.Fatal(n, err)
+	}
+	if _, err := j.GetInt32("over"); !errors.As(err, &re) || re.Type != "int32" {
+		t.Fatal(err)
+	}
+	if n, err := j.GetUint32("over"); err != nil || n != 1<<31 {
+		t.Fatal(n, err)
+	}
+	if _, err := j.GetInt16("frac"); !errors.Is(err, ErrNotInteger) {
+		t.Fatal(err)
+	}
+	if n, err := j.GetUint8("e"); err != nil || n != 200 {
+		t.Fatal(n, err)
+	}
+	if _, err := j.GetInt8("e"); !errors.As(err, &re) {
+		t.Fatal(err)
+	}
+	if n, err := j.GetUint16("e"); err != nil || n != 200 {
+		t.Fatal(n, err)
+	}
+	if _, err := j.Get("u8").Uint8Array(); !errors.As(err, &re) || re.Num != "256" {
+		t.Fatal(err)
+	}
+	if a, err := j.Get("u8").Uint16Array(); err != nil || !reflect.DeepEqual(a, []uint16{0, 255, 256}) {
+		t.Fatal(a, err)
+	}
+	if _, err := j.GetInt32("s"); err == nil {
+		t.Fatal("type mismatch")
+	}
+
+	j.TruncateFraction()
+	j.StringAsNumber()
+	if n, err := j.GetInt16("frac"); err != nil || n != 1 {
+		t.Fatal(n, err)
+	}
+	if n, err := j.GetInt32("s"); err != nil || n != 12 {
+		t.Fatal(n, err)
+	}
+	if a, err := (Json{tp: ARRAY, a: []Json{{tp: NUMBER, b: []byte("-2.5")}}, co: FractionToInt}).Int8Array(); err != nil || a[0] != -2 {
+		t.Fatal(a, err)
+	}
+	for _, tc := range []struct {
+		f    func(Json) error
+		want bool
+	}{
+		{func(j Json) error { _, err := j.UintArray(); return err }, false},
+		{func(j Json) error { _, err := j.Int32Array(); return err }, true},
+		{func(j Json) error { _, err := j.Int16Array(); return err }, true},
+		{func(j Json) error { _, err := j.Uint32Array(); return err }, false},
+	} {
+		v, _ := Unmarshal([]byte(`[1, -1]`))
+		if err := tc.f(v); (err == nil) != tc.want {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestMissing(t *testing.T) {
+	const in = `{"name": null, "tags": [null], "n": 1, "empty": {}, "none": []}`
+	j, err := Unmarshal([]byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, keys := range [][]interface{}{{"name"}, {"tags", 0}} {
+		if v, ok := j.Lookup(keys...); !ok || !v.IsNull() || v.IsMissing() || !j.Has(keys...) {
+			t.Fatal(keys, v, ok)
+		}
+	}
+	for _, keys := range [][]interface{}{{"age"}, {"tags", 1}, {"tags", -1}, {"empty", "a"}, {"none", 0}} {
+		if v, ok := j.Lookup(keys...); ok || !v.IsNull() || !v.IsMissing() || j.Has(keys...) {
+			t.Fatal(keys, v, ok)
+		}
+		v, err := Unmarshal([]byte(in), keys...)
+		if err != nil || !v.IsMissing() || v.Type() != NULL {
+			t.Fatal(keys, v, err)
+		}
+	}
+	if _, ok := j.Lookup("n", "a"); ok {
+		t.Fatal("type mismatch")
+	}
+	ret, err := UnmarshalPaths([]byte(in), []interface{}{"name"}, []interface{}{"age"}, []interface{}{"tags", 3})
+	if err != nil || ret[0].IsMissing() || !ret[1].IsMissing() || !ret[2].IsMissing() {
+		t.Fatal(ret, err)
+	}
+
+	if s := j.GetStringOr("x", "name"); s != "x" {
+		t.Fatal(s)
+	}
+	if n := j.GetIntOr(2, "n"); n != 1 {
+		t.Fatal(n)
+	}
+	if n := j.GetIntOr(2, "age"); n != 2 {
+		t.Fatal(n)
+	}
+	if f := j.GetFloatOr(.5, "name"); f != .5 {
+		t.Fatal(f)
+	}
+	j.AllAsBool()
+	if b := j.GetBoolOr(true, "name"); !b {
+		t.Fatal(b)
+	}
+	if b := j.GetBoolOr(false, "n"); !b {
+		t.Fatal(b)
+	}
+}
+
+func TestTypedErrors(t *testing.T) {
+	j, err := Unmarshal([]byte(`{"s": "x", "n": 1, "a": [1]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var te *TypeMismatchError
+	if _, err := j.GetInt("s"); !errors.As(err, &te) || te.Expected != NUMBER || te.Found != STRING {
+		t.Fatal(err)
+	}
+	if err := j.Get("a", "x").Error(); !errors.As(err, &te) || te.Expected != OBJECT || te.Found != ARRAY {
+		t.Fatal(err)
+	}
+	if err := j.Get(1.5).Error(); !errors.Is(err, ErrKeyType) {
+		t.Fatal(err)
+	}
+	if _, err := Unmarshal([]byte(`{}`), 1.5); !errors.Is(err, ErrKeyType) {
+		t.Fatal(err)
+	}
+	if _, err := j.Get("n").Len(); !errors.Is(err, ErrLen) {
+		t.Fatal(err)
+	}
+	if err := j.Get("n").EachOf().Error(); !errors.Is(err, ErrEachOf) {
+		t.Fatal(err)
+	}
+	if _, err := Unmarshal([]byte(`1`), Each); !errors.Is(err, ErrEachOf) {
+		t.Fatal(err)
+	}
+	for _, s := range []string{`{"a": [1`, `[`, `{`, `"abc`, `tru`, `{"a"`} {
+		_, err := Unmarshal([]byte(s))
+		if !errors.Is(err, ErrUnexpectedEOF) {
+			t.Fatal(s, err)
+		}
+		if s[0] == '{' {
+			if _, err = Unmarshal([]byte(s), "a"); !errors.Is(err, ErrUnexpectedEOF) {
+				t.Fatal(s, err)
+			}
+		}
+	}
+	if _, err := Unmarshal([]byte(`[1`)); !errors.Is(err, ErrArrayEOF) {
+		t.Fatal(err)
+	}
+	if _, err := (&Options{Strict: true}).Unmarshal([]byte("\"\xff\"")); !errors.Is(err, ErrInvalidUTF8) {
+		t.Fatal(err)
+	}
+	for _, s := range []string{`"\q"`, `"\u12"`, "\"\t\""} {
+		if _, err := (&Options{Strict: true}).Unmarshal([]byte(s)); !errors.Is(err, ErrInvalidString) {
+			t.Fatal(s, err)
+		}
+	}
+}
+
+func TestErrorPath(t *testing.T) {
+	j, err := Unmarshal([]byte(`{"users": [{}, {}, {}, {"address": [], "age": "1", "name": 1}], "m": {"a": {}, "b": 1}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var te *TypeMismatchError
+	err = j.Get("users", 3, "address", "zip").Error()
+	if !errors.As(err, &te) || err.Error() != "users[3].address: type mismatch: expected OBJECT, found ARRAY" {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(te.Path, []interface{}{"users", 3, "address"}) {
+		t.Fatal(te.Path)
+	}
+	if _, err := j.Get("users", 3, "address", "zip").Int(); err == nil || err.Error() != "users[3].address: type mismatch: expected OBJECT, found ARRAY" {
+		t.Fatal(err)
+	}
+	if _, err := j.GetInt("users", 3, "age"); err == nil || err.Error() != "users[3].age: type mismatch: expected NUMBER, found STRING" {
+		t.Fatal(err)
+	}
+	if _, err := j.GetUint8("users", 3, "age"); err == nil || err.Error() != "users[3].age: type mismatch: expected NUMBER, found STRING" {
+		t.Fatal(err)
+	}
+	if _, err := j.GetString("users", Each, "name"); err == nil || err.Error() != "users[*].name: type mismatch: expected STRING, found ARRAY" {
+		t.Fatal(err)
+	}
+	if err := j.Get("users", Each, "name", "x").Error(); err == nil || err.Error() != "users[0].name: type mismatch: expected OBJECT, found NULL" {
+		t.Fatal(err)
+	}
+	if err := j.Get("m").EachOf("x").Error(); err == nil || err.Error() != "b: type mismatch: expected OBJECT, found NUMBER" {
+		t.Fatal(err)
+	}
+	if err := j.Member("m").Member("b").Member("c").Error(); err == nil || err.Error() != "type mismatch: expected OBJECT, found NUMBER" {
+		t.Fatal(err)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	j, err := Unmarshal([]byte(`{"name": "Tom", "age": "x", "id": 4294967296, "tags": ["a"], "ok": true,
+		"address": {"city": "X"}, "scores": [1, 2], "note": null}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := j.Extract()
+	if s := x.String("name"); s != "Tom" {
+		t.Fatal(s)
+	}
+	if !x.Bool("ok") || x.Float("scores", 1) != 2 || x.Uint("id") != 1<<32 {
+		t.Fatal("values")
+	}
+	if a := x.StringArray("tags"); !reflect.DeepEqual(a, []string{"a"}) {
+		t.Fatal(a)
+	}
+	if a := x.IntArray("scores"); !reflect.DeepEqual(a, []int64{1, 2}) {
+		t.Fatal(a)
+	}
+	if v := x.Get("note"); !v.IsNull() {
+		t.Fatal(v)
+	}
+	if err := x.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	x.Int("age")
+	x.Int32("id")
+	x.String("email")
+	x.String("address", "zip")
+	x.String("name", "first")
+	x.String("note")
+	err = x.Err()
+	errs, ok := err.(Errors)
+	if !ok || len(errs) != 6 {
+		t.Fatal(err)
+	}
+	want := []string{
+		"age: type mismatch: expected NUMBER, found STRING",
+		"id: number 4294967296 out of range of int32",
+		"email: missing",
+		"address.zip: missing",
+		"name: type mismatch: expected OBJECT, found STRING",
+		"note: type mismatch: expected STRING, found NULL",
+	}
+	for i := range errs {
+		if errs[i].Error() != want[i] {
+			t.Fatal(i, errs[i])
+		}
+	}
+	var re *RangeError
+	if !errors.As(errs[1], &re) || !errors.Is(errs[2], ErrMissing) {
+		t.Fatal(errs)
+	}
+	if !strings.HasPrefix(err.Error(), "6 errors: age: type mismatch") {
+		t.Fatal(err)
+	}
+
+	j, _ = Unmarshal([]byte(`{"big": 1e19, "frac": 2.5, "ok": 1e3}`))
+	x = j.Extract()
+	if x.Int("big") != 0 || x.Int("frac") != 0 || x.Int("ok") != 1000 {
+		t.Fatal("int")
+	}
+	errs, _ = x.Err().(Errors)
+	if len(errs) != 2 || errs[0].Error() != "big: number 1e19 out of range of int64" || !errors.Is(errs[1], ErrNotInteger) {
+		t.Fatal(errs)
+	}
+}
+
+func TestCoercion(t *testing.T) {
+	j, err := Unmarshal([]byte(`{"id": "12", "n": 1.50, "t": "true", "f": "false", "null": null, "empty": "",
+		"one": "a", "ids": ["1", 2], "yes": "yes"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := j.GetInt("id"); err == nil {
+		t.Fatal("no coercion")
+	}
+	c := j.Coerce(StringToNumber | NumberToString | StringToBool | NullToZero | SingleToArray | EmptyStringToNull)
+	if n, err := c.GetInt("id"); err != nil || n != 12 {
+		t.Fatal(n, err)
+	}
+	if s, err := c.GetString("n"); err != nil || s != "1.50" {
+		t.Fatal(s, err)
+	}
+	if b, err := c.GetBool("t"); err != nil || !b {
+		t.Fatal(b, err)
+	}
+	if b, err := c.GetBool("f"); err != nil || b {
+		t.Fatal(b, err)
+	}
+	if _, err := c.GetBool("yes"); err == nil {
+		t.Fatal("yes")
+	}
+	for _, k := range []string{"null", "empty", "missing"} {
+		if n, err := c.GetInt(k); err != nil || n != 0 {
+			t.Fatal(k, n, err)
+		}
+		if f, err := c.GetFloat(k); err != nil || f != 0 {
+			t.Fatal(k, f, err)
+		}
+		if b, err := c.GetBool(k); err != nil || b {
+			t.Fatal(k, b, err)
+		}
+		if s, err := c.GetString(k); err != nil || s != "" {
+			t.Fatal(k, s, err)
+		}
+		if a, err := c.Get(k).IntArray(); err != nil || len(a) != 0 {
+			t.Fatal(k, a, err)
+		}
+		if !c.Get(k).IsNull() {
+			t.Fatal(k)
+		}
+		// the defaults win over NullToZero
+		if c.GetIntOr(42, k) != 42 || c.GetFloatOr(.5, k) != .5 || !c.GetBoolOr(true, k) {
+			t.Fatal(k)
+		}
+		if s := c.GetStringOr("x", k); k != "empty" && s != "x" || k == "empty" && s != "" {
+			t.Fatal(k, s)
+		}
+	}
+	if a, err := c.Get("one").StringArray(); err != nil || !reflect.DeepEqual(a, []string{"a"}) {
+		t.Fatal(a, err)
+	}
+	if a, err := c.Get("ids").IntArray(); err != nil || !reflect.DeepEqual(a, []int64{1, 2}) {
+		t.Fatal(a, err)
+	}
+	if a, err := c.Get("ids").StringArray(); err != nil || !reflect.DeepEqual(a, []string{"1", "2"}) {
+		t.Fatal(a, err)
+	}
+	if c.Coercion()&StringToNumber == 0 || j.Coercion() != 0 {
+		t.Fatal(c.Coercion(), j.Coercion())
+	}
+
+	// without NullToZero
+	c = j.Coerce(EmptyStringToNull | StringToNumber)
+	var te *TypeMismatchError
+	if _, err := c.GetInt("empty"); !errors.As(err, &te) || te.Found != STRING {
+		t.Fatal(err)
+	}
+	if s, err := c.GetString("empty"); err != nil || s != "" {
+		t.Fatal(s, err)
+	}
+
+	j.SetCoercion(StringToNumber)
+	j.AllAsBool()
+	if j.Coercion() != StringToNumber|AnyToBool {
+		t.Fatal(j.Coercion())
+	}
+	if b, err := j.GetBool("yes"); err != nil || !b {
+		t.Fatal(b, err)
+	}
+}
+
+func TestMatchNames(t *testing.T) {
+	const in = `{"UserID": 1, "user_name": "Tom", "items": [{"Item-Id": 1}, {"itemId": 2}]}`
+	j, err := Unmarshal([]byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j.Has("userid") {
+		t.Fatal("exact")
+	}
+	j.MatchNames(MatchFold)
+	if n, err := j.GetInt("userId"); err != nil || n != 1 {
+		t.Fatal(n, err)
+	}
+	if j.Has("userName") {
+		t.Fatal("fold")
+	}
+	j.MatchNames(MatchNormalized)
+	if s, err := j.GetString("userName"); err != nil || s != "Tom" {
+		t.Fatal(s, err)
+	}
+	if a, err := j.Get("Items", Each, "item_id").IntArray(); err != nil || !reflect.DeepEqual(a, []int64{1, 2}) {
+		t.Fatal(a, err)
+	}
+	if a, err := j.Get("items").EachOf("ITEMID").IntArray(); err != nil || !reflect.DeepEqual(a, []int64{1, 2}) {
+		t.Fatal(a, err)
+	}
+
+	for _, opt := range []*Options{{MatchNames: MatchNormalized}, {MatchNames: MatchNormalized, Lazy: true}} {
+		v, err := opt.Unmarshal([]byte(in), "user-id")
+		if n, _ := v.Int(); err != nil || n != 1 {
+			t.Fatal(n, err)
+		}
+		v, err = opt.Unmarshal([]byte(in), "items", Each, "itemID")
+		if a, _ := v.IntArray(); err != nil || !reflect.DeepEqual(a, []int64{1, 2}) {
+			t.Fatal(a, err)
+		}
+		v, err = opt.Unmarshal([]byte(in))
+		if n, _ := v.GetInt("items", 1, "ITEM_ID"); err != nil || n != 2 {
+			t.Fatal(n, err)
+		}
+		ret, err := opt.UnmarshalPaths([]byte(in), []interface{}{"userId"}, []interface{}{"items", Each, "item_id"}, []interface{}{"Items"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n, _ := ret[0].Int(); n != 1 {
+			t.Fatal(ret[0])
+		}
+		if a, _ := ret[1].IntArray(); !reflect.DeepEqual(a, []int64{1, 2}) {
+			t.Fatal(ret[1])
+		}
+		if n, _ := ret[2].GetInt(0, "itemId"); n != 1 {
+			t.Fatal(ret[2])
+		}
+	}
+
+	// the first member matched wins, duplicates are of the exact name
+	const dup = `{"C": [0], "c": "00", "C": [1], "c": "11"}`
+	for _, d := range []DuplicatePolicy{DuplicateFirstWins, DuplicateLastWins, DuplicateKeepAll} {
+		for _, m := range []NameMatch{MatchFold, MatchNormalized} {
+			opt := &Options{MatchNames: m, Duplicates: d}
+			j, err := opt.Unmarshal([]byte(dup))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := j.Get("c", 0)
+			if want.Type() != NUMBER {
+				t.Fatal(d, m, want.Type())
+			}
+			v, err := opt.Unmarshal([]byte(dup), "c", 0)
+			if err != nil || string(v.b) != string(want.b) {
+				t.Fatal(d, m, string(v.b), err)
+			}
+			ret, err := opt.UnmarshalPaths([]byte(dup), []interface{}{"c", 0})
+			if err != nil || string(ret[0].b) != string(want.b) {
+				t.Fatal(d, m, string(ret[0].b), err)
+			}
+		}
+	}
+}
+
+func TestGeneric(t *testing.T) {
+	const in = `{"id": 7, "m": [[1, 2.5], [], [3]], "tags": ["a", null], "scores": {"a": 1, "b": 2}, "big": 300, "sid": "12",
+		"ints": [1, 300], "nested": {"a": [-1]}, "users": [{"name": "Tom"}, {"name": 1}]}`
+	j, err := Unmarshal([]byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := Get[int](j, "id"); err != nil || n != 7 {
+		t.Fatal(n, err)
+	}
+	if m, err := Get[[][]float64](j, "m"); err != nil || !reflect.DeepEqual(m, [][]float64{{1, 2.5}, {}, {3}}) {
+		t.Fatal(m, err)
+	}
+	if a, err := Get[[]*string](j, "tags"); err != nil || len(a) != 2 || *a[0] != "a" || a[1] != nil {
+		t.Fatal(a, err)
+	}
+	if m, err := Get[map[string]int32](j, "scores"); err != nil || !reflect.DeepEqual(m, map[string]int32{"a": 1, "b": 2}) {
+		t.Fatal(m, err)
+	}
+	if p, err := Get[*int](j, "none"); err != nil || p != nil {
+		t.Fatal(p, err)
+	}
+	if v, err := Get[Json](j, "users", 0); err != nil || v.GetStringOr("", "name") != "Tom" {
+		t.Fatal(v, err)
+	}
+	if n, err := Get[Number](j, "big"); err != nil || n != "300" {
+		t.Fatal(n, err)
+	}
+
+	var re *RangeError
+	if _, err := Get[uint8](j, "big"); !errors.As(err, &re) || re.Type != "uint8" {
+		t.Fatal(err)
+	}
+	if _, err := Get[[]int8](j, "ints"); !errors.As(err, &re) || err.Error() != "ints[1]: number 300 out of range of int8" {
+		t.Fatal(err)
+	}
+	if _, err := Get[map[string][]uint](j, "nested"); !errors.As(err, &re) || err.Error() != "nested.a[0]: number -1 out of range of uint" {
+		t.Fatal(err)
+	}
+	var te *TypeMismatchError
+	if _, err := Get[[]float64](j, "m"); !errors.As(err, &te) || err.Error() != "m[0]: type mismatch: expected NUMBER, found ARRAY" {
+		t.Fatal(err)
+	}
+	type user struct{ Name string }
+	if _, err := Get[[]map[string]string](j, "users"); !errors.As(err, &te) || err.Error() != "users[1].name: type mismatch: expected STRING, found NUMBER" {
+		t.Fatal(err)
+	}
+	if _, err := Get[user](j, "users", 0); !errors.Is(err, ErrUnsupportedType) {
+		t.Fatal(err)
+	}
+	if _, err := Get[int](j, "sid"); err == nil {
+		t.Fatal("coercion")
+	}
+	if n, err := Get[int](j.Coerce(StringToNumber), "sid"); err != nil || n != 12 {
+		t.Fatal(n, err)
+	}
+	if a, err := Get[[]string](j.Coerce(NullToZero), "none"); err != nil || a == nil || len(a) != 0 {
+		t.Fatal(a, err)
+	}
+}
+
+func TestMaps(t *testing.T) {
+	const in = `{"names": {"a": "x", "b": "y"}, "scores": {"a": 1, "b": 2.5, "a": 3}, "flags": {"on": true, "off": false},
+		"bad": {"a": 1, "b": "2"}, "list": [1], "huge": {"a": 1, "b": 1e40}, "esc": {"a": "", "b": "\q"}}`
+	j, err := Unmarshal([]byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m, err := j.GetStringMap("names"); err != nil || !reflect.DeepEqual(m, map[string]string{"a": "x", "b": "y"}) {
+		t.Fatal(m, err)
+	}
+	if m, err := j.GetFloatMap("scores"); err != nil || !reflect.DeepEqual(m, map[string]float64{"a": 1, "b": 2.5}) {
+		t.Fatal(m, err)
+	}
+	if m, err := j.GetBoolMap("flags"); err != nil || !reflect.DeepEqual(m, map[string]bool{"on": true, "off": false}) {
+		t.Fatal(m, err)
+	}
+	if m, err := j.GetMap("scores"); err != nil || len(m) != 2 || m["b"].Type() != NUMBER {
+		t.Fatal(m, err)
+	}
+	var te *TypeMismatchError
+	if _, err := j.GetIntMap("bad"); !errors.As(err, &te) || err.Error() != "bad.b: type mismatch: expected NUMBER, found STRING" {
+		t.Fatal(err)
+	}
+	if m, err := j.Get("bad").Coerce(StringToNumber).IntMap(); err != nil || !reflect.DeepEqual(m, map[string]int64{"a": 1, "b": 2}) {
+		t.Fatal(m, err)
+	}
+	if _, err := j.GetIntMap("list"); !errors.As(err, &te) || err.Error() != "list: type mismatch: expected OBJECT, found ARRAY" {
+		t.Fatal(err)
+	}
+	if _, err := j.GetIntMap("huge"); err == nil || !strings.HasPrefix(err.Error(), "huge.b: ") {
+		t.Fatal(err)
+	}
+	if _, err := j.GetStringMap("esc"); !errors.Is(err, ErrInvalidString) || !strings.HasPrefix(err.Error(), "esc.b: ") {
+		t.Fatal(err)
+	}
+
+	j, err = (&Options{Duplicates: DuplicateLastWins}).Unmarshal([]byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m, err := j.GetIntMap("scores"); err != nil || !reflect.DeepEqual(m, map[string]int64{"a": 3, "b": 2}) {
+		t.Fatal(m, err)
+	}
+}
+
+func TestTime(t *testing.T) {
+	const in = `{"created": "2015-07-29T18:24:34Z", "day": "2015-07-29", "ts": 1438194274, "ms": 1438194274500,
+		"frac": -1.5, "sts": "1438194274", "ttl": "1m30s", "secs": 2.5, "ssecs": "90", "days": ["2015-07-29", "2015-07-30"], "bad": 1}`
+	j, err := Unmarshal([]byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2015, 7, 29, 18, 24, 34, 0, time.UTC)
+	if tm, err := j.GetTime("", "created"); err != nil || !tm.Equal(want) {
+		t.Fatal(tm, err)
+	}
+	if tm, err := j.GetTime("2006-01-02", "day"); err != nil || !tm.Equal(want.Truncate(24*time.Hour)) {
+		t.Fatal(tm, err)
+	}
+	if tm, err := j.GetUnixTime(time.Second, "ts"); err != nil || !tm.Equal(want) {
+		t.Fatal(tm, err)
+	}
+	if tm, err := j.GetUnixTime(time.Millisecond, "ms"); err != nil || !tm.Equal(want.Add(500*time.Millisecond)) {
+		t.Fatal(tm, err)
+	}
+	if tm, err := j.GetUnixTime(time.Second, "frac"); err != nil || !tm.Equal(time.Unix(-2, 5e8)) {
+		t.Fatal(tm, err)
+	}
+	if _, err := j.GetUnixTime(time.Second, "sts"); err == nil {
+		t.Fatal("string")
+	}
+	if tm, err := j.Coerce(StringToNumber).GetUnixTime(time.Second, "sts"); err != nil || !tm.Equal(want) {
+		t.Fatal(tm, err)
+	}
+	if d, err := j.GetDuration("ttl"); err != nil || d != 90*time.Second {
+		t.Fatal(d, err)
+	}
+	if d, err := j.GetDuration("secs"); err != nil || d != 2500*time.Millisecond {
+		t.Fatal(d, err)
+	}
+	if d, err := j.Coerce(StringToNumber).GetDuration("ssecs"); err != nil || d != 90*time.Second {
+		t.Fatal(d, err)
+	}
+	var re *RangeError
+	if _, err := j.GetDuration("ms"); !errors.As(err, &re) || re.Type != "time.Duration" {
+		t.Fatal(err)
+	}
+	if a, err := j.Get("days").TimeArray("2006-01-02"); err != nil || len(a) != 2 || a[1].Day() != 30 {
+		t.Fatal(a, err)
+	}
+	if _, err := j.GetTime("", "bad"); err == nil || err.Error() != "bad: type mismatch: expected STRING, found NUMBER" {
+		t.Fatal(err)
+	}
+}
+
+func TestBytes(t *testing.T) {
+	const in = `{"std": "aGk/Pz8+", "url": "aGk_Pz8-", "raw": "aGk", "esc": "aGk\/Pz8+", "hex": "68693F",
+		"images": [{"data": "aGk!"}], "n": 1}`
+	j, err := Unmarshal([]byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		enc  Encoding
+		key  string
+		want string
+	}{
+		{Base64, "std", "hi???>"},
+		{Base64URL, "url", "hi???>"},
+		{RawBase64, "raw", "hi"},
+		{RawBase64URL, "raw", "hi"},
+		{Base64, "esc", "hi???>"},
+		{Hex, "hex", "hi?"},
+	} {
+		if b, err := j.GetBytes(c.enc, nil, c.key); err != nil || string(b) != c.want {
+			t.Fatal(c.enc, string(b), err)
+		}
+	}
+	buf := make([]byte, 0, 64)
+	b, err := j.GetBytes(Base64, append(buf, 'x'), "std")
+	if err != nil || string(b) != "xhi???>" || &b[0] != &buf[:1][0] {
+		t.Fatal(string(b), err)
+	}
+	var ce base64.CorruptInputError
+	if _, err := j.GetBytes(Base64, nil, "images", 0, "data"); !errors.As(err, &ce) || !strings.HasPrefix(err.Error(), "images[0].data: ") {
+		t.Fatal(err)
+	}
+	if _, err := j.GetBytes(Hex, nil, "std"); err == nil || !strings.HasPrefix(err.Error(), "std: ") {
+		t.Fatal(err)
+	}
+	if _, err := j.GetBytes(Base64, nil, "n"); err == nil || err.Error() != "n: type mismatch: expected STRING, found NUMBER" {
+		t.Fatal(err)
+	}
+}
+
+func TestInvalidStrings(t *testing.T) {
+	const in = `{"esc": "a\qb", "u": "a\u12zz", "lone": "a\ud800b", "pair": "😀", "ctrl": "a	b", "utf8": "a` + "\xff" + `b",
+		"ok": "a\tb", "obj": {"a": 0, "k\q": 1}}`
+	j, err := Unmarshal([]byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, err := j.GetString("pair"); err != nil || s != "\U0001F600" {
+		t.Fatal(s, err)
+	}
+	if s, err := j.GetString("ok"); err != nil || s != "a\tb" {
+		t.Fatal(s, err)
+	}
+	for _, k := range []string{"esc", "u", "lone", "ctrl"} {
+		if s, err := j.GetString(k); !errors.Is(err, ErrInvalidString) || s != "" {
+			t.Fatal(k, s, err)
+		}
+	}
+	if _, err := j.GetString("utf8"); !errors.Is(err, ErrInvalidUTF8) {
+		t.Fatal(err)
+	}
+	if _, err := j.Get("obj").Keys(); !errors.Is(err, ErrInvalidString) {
+		t.Fatal(err)
+	}
+	// only the lookups of the invalid name fail, with or without keys to Unmarshal
+	if n, err := j.GetInt("obj", "a"); err != nil || n != 0 {
+		t.Fatal(n, err)
+	}
+	if v := j.Get("obj", "k�"); !errors.Is(v.Error(), ErrInvalidString) {
+		t.Fatal(v.Error())
+	}
+	if v, err := Unmarshal([]byte(in), "obj", "a"); err != nil || v.Type() != NUMBER {
+		t.Fatal(v, err)
+	}
+	if _, err := Unmarshal([]byte(in), "obj", "k�"); !errors.Is(err, ErrInvalidString) {
+		t.Fatal(err)
+	}
+	ret, err := UnmarshalPaths([]byte(in), []interface{}{"obj", "a"})
+	if err != nil || ret[0].Type() != NUMBER {
+		t.Fatal(ret, err)
+	}
+	if _, err := UnmarshalPaths([]byte(in), []interface{}{"obj", "a"}, []interface{}{"obj", "k�"}); !errors.Is(err, ErrInvalidString) {
+		t.Fatal(err)
+	}
+
+	j.InvalidStrings(StringReplace)
+	for k, want := range map[string]string{"esc": "a�b", "u": "a�12zz", "lone": "a�b", "ctrl": "a�b", "utf8": "a�b"} {
+		if s, err := j.GetString(k); err != nil || s != want {
+			t.Fatal(k, s, err)
+		}
+	}
+	if n, err := j.GetInt("obj", "k�"); err != nil || n != 1 {
+		t.Fatal(n, err)
+	}
+
+	j, err = (&Options{InvalidStrings: StringRaw}).Unmarshal([]byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, want := range map[string]string{"esc": `a\qb`, "u": `a\u12zz`, "lone": `a\ud800b`, "ctrl": "a\tb", "utf8": "a\xffb"} {
+		if s, err := j.GetString(k); err != nil || s != want {
+			t.Fatal(k, s, err)
+		}
+	}
+	if n, err := j.GetInt("obj", `k\q`); err != nil || n != 1 {
+		t.Fatal(n, err)
+	}
+	if keys, err := j.Get("obj").Keys(); err != nil || !reflect.DeepEqual(keys, []string{"a", `k\q`}) {
+		t.Fatal(keys, err)
+	}
+
+	// the escapes of JSON5 are invalid in JSON
+	for _, esc := range []string{`\x41`, `\v`, `\0`, "\\\n"} {
+		in := []byte(`"a` + esc + `"`)
+		v, _ := Unmarshal(in)
+		if _, err := v.String(); !errors.Is(err, ErrInvalidString) {
+			t.Fatal(esc, err)
+		}
+		v, _ = (&Options{JSON5: true}).Unmarshal(in)
+		if _, err := v.String(); err != nil {
+			t.Fatal(esc, err)
+		}
+	}
+	if v, _ := (&Options{InvalidStrings: StringReplace}).Unmarshal([]byte(`{"\x41": "\x41"}`)); v.GetStringOr("", "�41") != "�41" {
+		t.Fatal(v)
+	}
+
+	large := `{"k\q": -1`
+	for i := 0; i < 40; i++ {
+		large += fmt.Sprintf(`, "k%d": %d`, i, i)
+	}
+	large += "}"
+	for _, sp := range []StringPolicy{StringReject, StringReplace, StringRaw} {
+		j, err := (&Options{InvalidStrings: sp}).Unmarshal([]byte(large))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n, err := j.GetInt("k39"); err != nil || n != 39 {
+			t.Fatal(sp, n, err)
+		}
+		if _, err := j.GetInt("k�"); sp == StringReject && !errors.Is(err, ErrInvalidString) {
+			t.Fatal(err)
+		}
+		if n, err := j.GetInt(`k\q`); sp == StringRaw && (err != nil || n != -1) {
+			t.Fatal(n, err)
+		}
+	}
+}
+
+func TestUnmarshalPathAllocs(t *testing.T) {
+	// the parser stays on the stack, see lazy.opt
+	if n := testing.AllocsPerRun(100, func() { Unmarshal(mediumFixture, "person", "github", "followers") }); n != 0 {
+		t.Fatal(n)
+	}
+}
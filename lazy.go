@@ -0,0 +1,55 @@
+package jsonport
+
+import "sync"
+
+// lazy is an OBJECT or ARRAY parsed on first access, see Options.Lazy.
+type lazy struct {
+	once sync.Once
+
+	opt   Options // a copy, so that the parser does not escape
+	data  []byte  // the whole input for locating errors
+	raw   []byte  // the object or array
+	depth int     // nesting depth of raw
+
+	m   []kv
+	x   *memberIndex
+	a   []Json
+	err error
+}
+
+// lazyValue skips the object or array at b and returns it as a lazy Json of type t.
+func (p *parser) lazyValue(b []byte, t Type) (Json, int, error) {
+	n, err := p.jsonskip(b)
+	if err != nil {
+		return Json{}, n, err
+	}
+	l := &lazy{opt: p.opt, data: p.data, raw: b[:n], depth: p.depth}
+	return Json{tp: t, b: l.raw, lz: l}, n, nil
+}
+
+func (l *lazy) load() ([]kv, []Json, error) {
+	l.once.Do(func() {
+		p := &parser{opt: l.opt, data: l.data, depth: l.depth}
+		if l.raw[0] == '{' {
+			l.m, _, l.err = p.parseObject(l.raw, false)
+			l.x = newMemberIndex(l.m)
+		} else {
+			l.a, _, l.err = p.parseArray(l.raw)
+		}
+	})
+	return l.m, l.a, l.err
+}
+
+// load parses j if it is lazy, the result is cached for all copies of j.
+func (j *Json) load() error {
+	if j.lz == nil {
+		return nil
+	}
+	var err error
+	j.m, j.a, err = j.lz.load()
+	j.x = j.lz.x
+	if err != nil && j.err == nil {
+		j.err = err
+	}
+	return err
+}
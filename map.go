@@ -0,0 +1,101 @@
+package jsonport
+
+// mapOf converts the values of the members of j by f to a map.
+// The first member wins for duplicate names, as Member does,
+// see Options.Duplicates for the other policies.
+func mapOf[T any](j Json, f func(Json) (T, error)) (map[string]T, error) {
+	if j.tp != OBJECT {
+		if j.zero() {
+			return map[string]T{}, nil
+		}
+		return nil, j.mismatch(OBJECT)
+	}
+	if err := j.load(); err != nil {
+		return nil, err
+	}
+	ret := make(map[string]T, len(j.m))
+	for i := range j.m {
+		k, err := j.m[i].name(j.sp, j.json5)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := ret[k]; ok {
+			continue
+		}
+		v, err := f(j.returnj(j.m[i].v))
+		if err != nil {
+			return nil, atPath(err, []interface{}{k})
+		}
+		ret[k] = v
+	}
+	return ret, nil
+}
+
+// Map converts current json value to map[string]Json.
+// error is returned if value type not equal to OBJECT.
+// For duplicate names, the value of the first member is in the map.
+func (j Json) Map() (map[string]Json, error) {
+	return mapOf(j, func(v Json) (Json, error) { return v, nil })
+}
+
+// StringMap converts current json value to map[string]string.
+// error is returned if any member type not equal to STRING,
+// the error has the member name, in Path of a *TypeMismatchError.
+func (j Json) StringMap() (map[string]string, error) {
+	return mapOf(j, Json.String)
+}
+
+// IntMap converts current json value to map[string]int64, see StringMap.
+func (j Json) IntMap() (map[string]int64, error) {
+	return mapOf(j, Json.Int)
+}
+
+// FloatMap converts current json value to map[string]float64, see StringMap.
+func (j Json) FloatMap() (map[string]float64, error) {
+	return mapOf(j, Json.Float)
+}
+
+// BoolMap converts current json value to map[string]bool, see StringMap.
+func (j Json) BoolMap() (map[string]bool, error) {
+	return mapOf(j, Json.Bool)
+}
+
+// GetMap converts json value specified by keys to map[string]Json,
+// it is equal to Get(keys...).Map()
+func (j Json) GetMap(keys ...interface{}) (map[string]Json, error) {
+	v := j.Get(keys...)
+	ret, err := v.Map()
+	return ret, v.at(err, keys)
+}
+
+// GetStringMap converts json value specified by keys to map[string]string,
+// it is equal to Get(keys...).StringMap()
+func (j Json) GetStringMap(keys ...interface{}) (map[string]string, error) {
+	v := j.Get(keys...)
+	ret, err := v.StringMap()
+	return ret, v.at(err, keys)
+}
+
+// GetIntMap converts json value specified by keys to map[string]int64,
+// it is equal to Get(keys...).IntMap()
+func (j Json) GetIntMap(keys ...interface{}) (map[string]int64, error) {
+	v := j.Get(keys...)
+	ret, err := v.IntMap()
+	return ret, v.at(err, keys)
+}
+
+// GetFloatMap converts json value specified by keys to map[string]float64,
+// it is equal to Get(keys...).FloatMap()
+func (j Json) GetFloatMap(keys ...interface{}) (map[string]float64, error) {
+	v := j.Get(keys...)
+	ret, err := v.FloatMap()
+	return ret, v.at(err, keys)
+}
+
+// GetBoolMap converts json value specified by keys to map[string]bool,
+// it is equal to Get(keys...).BoolMap()
+func (j Json) GetBoolMap(keys ...interface{}) (map[string]bool, error) {
+	v := j.Get(keys...)
+	ret, err := v.BoolMap()
+	return ret, v.at(err, keys)
+}
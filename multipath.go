@@ -0,0 +1,572 @@
+package jsonport
+
+import "fmt"
+
+// UnmarshalPaths is like calling Unmarshal(data, path...) for every path,
+// but walks data only once. It descends into the subtrees some path needs,
+// everything else is skipped without being parsed.
+//
+// The i-th Json returned is the value of paths[i]. A value not found is NULL
+// and IsMissing, and a path which does not fit the document, like a member
+// name on an ARRAY, leaves the error in Json.Error() of that value only.
+func UnmarshalPaths(data []byte, paths ...[]interface{}) ([]Json, error) {
+	return (*Options)(nil).UnmarshalPaths(data, paths...)
+}
+
+// UnmarshalPaths is like the package level UnmarshalPaths, but parses data with options o.
+func (o *Options) UnmarshalPaths(data []byte, paths ...[]interface{}) ([]Json, error) {
+	p := newParser(o, data)
+	if max := p.opt.MaxBytes; max > 0 && len(data) > max {
+		return nil, &LimitError{Limit: "MaxBytes", Max: max, Offset: int64(max)}
+	}
+	root := &pathNode{}
+	for i, path := range paths {
+		if err := root.add(i, path); err != nil {
+			return nil, err
+		}
+	}
+	ret := make([]Json, len(paths))
+	data = p.data
+	n, err := p.walk(data, paths, root, func(i int, j Json) {
+		j.nm = p.opt.MatchNames
+		j.sp = p.opt.InvalidStrings
+		j.json5 = p.opt.JSON5
+		ret[i] = j
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(root.leaves) != 0 && n+p.skipspace(data[n:]) != len(data) {
+		return ret, ErrMoreBytes
+	}
+	return ret, nil
+}
+
+// pathNode is a node in the trie of paths passed to UnmarshalPaths.
+type pathNode struct {
+	depth  int
+	leaves []int // paths ending at this node
+	names  []int // paths ending with ParseMemberNamesOnly at this node
+
+	members  map[string]*pathNode
+	elements map[int]*pathNode
+	every    *pathNode // for Each
+
+	seen bool // set once the value of the node has been walked
+	pos  int  // the position of the member name walked for the node, see walkObject
+}
+
+func (n *pathNode) add(i int, path []interface{}) error {
+	for _, k := range path[n.depth:] {
+		if name, err := parseMemberName(k); err == nil {
+			if name == ParseMemberNamesOnly {
+				n.names = append(n.names, i)
+				return nil
+			}
+			if name == Each {
+				if n.every == nil {
+					n.every = &pathNode{depth: n.depth + 1}
+				}
+				n = n.every
+				continue
+			}
+			if n.members == nil {
+				n.members = make(map[string]*pathNode)
+			}
+			c := n.members[name]
+			if c == nil {
+				c = &pathNode{depth: n.depth + 1}
+				n.members[name] = c
+			}
+			n = c
+		} else if index, err := parseArrayIndex(k); err == nil {
+			if n.elements == nil {
+				n.elements = make(map[int]*pathNode)
+			}
+			c := n.elements[index]
+			if c == nil {
+				c = &pathNode{depth: n.depth + 1}
+				n.elements[index] = c
+			}
+			n = c
+		} else {
+			return ErrKeyType
+		}
+	}
+	n.leaves = append(n.leaves, i)
+	return nil
+}
+
+// member calls f with the children of n for the member name k,
+// there may be more than one of them if m is not MatchExact.
+func (n *pathNode) member(m NameMatch, k string, f func(c *pathNode)) {
+	if m == MatchExact {
+		if c := n.members[k]; c != nil {
+			f(c)
+		}
+		return
+	}
+	for name, c := range n.members {
+		if m.match(k, name) {
+			f(c)
+		}
+	}
+}
+
+// each calls f with the index of every path under n.
+func (n *pathNode) each(f func(i int)) {
+	for _, i := range n.leaves {
+		f(i)
+	}
+	for _, i := range n.names {
+		f(i)
+	}
+	for _, c := range n.members {
+		c.each(f)
+	}
+	for _, c := range n.elements {
+		c.each(f)
+	}
+	if n.every != nil {
+		n.every.each(f)
+	}
+}
+
+// reset clears the seen flags under n, so that it can be walked again.
+func (n *pathNode) reset() {
+	n.seen = false
+	for _, c := range n.members {
+		c.reset()
+	}
+	for _, c := range n.elements {
+		c.reset()
+	}
+	if n.every != nil {
+		n.every.reset()
+	}
+}
+
+// eachOf collects the values of the paths under n.every for every value
+// of the container being walked, as j.EachOf does.
+type eachOf struct {
+	n   *pathNode
+	a   map[int][]Json
+	err map[int]error
+}
+
+func newEachOf(n *pathNode) *eachOf {
+	if n.every == nil {
+		return nil
+	}
+	e := &eachOf{n: n.every, a: make(map[int][]Json), err: make(map[int]error)}
+	n.every.each(func(k int) { e.a[k] = []Json{} })
+	return e
+}
+
+// walkEach walks the value at b as the pos-th value of the container,
+// a value already collected at pos is replaced. key is the index or
+// the member name of the value, in place of Each in the path of errors.
+func (p *parser) walkEach(b []byte, paths [][]interface{}, e *eachOf, pos int, key interface{}) (int, error) {
+	e.n.reset()
+	return p.walk(b, paths, e.n, func(k int, j Json) {
+		if j.err != nil && e.err[k] == nil {
+			e.err[k] = eachAt(j.err, e.n.depth-1, key)
+		}
+		if pos < len(e.a[k]) {
+			e.a[k][pos] = j
+		} else {
+			e.a[k] = append(e.a[k], j)
+		}
+	})
+}
+
+// eachAt replaces Each at d in the path of err with key.
+func eachAt(err error, d int, key interface{}) error {
+	e, ok := err.(*TypeMismatchError)
+	if !ok || len(e.Path) <= d || e.Path[d] != Each {
+		return err
+	}
+	c := *e
+	c.Path = append([]interface{}(nil), e.Path...)
+	c.Path[d] = key
+	return &c
+}
+
+func (e *eachOf) done(set func(int, Json)) {
+	if e == nil {
+		return
+	}
+	for k, a := range e.a {
+		if err := e.err[k]; err != nil {
+			set(k, Json{err: err})
+		} else {
+			set(k, Json{tp: ARRAY, a: a})
+		}
+	}
+}
+
+// walk walks the value at b for the paths under n, results are passed to set.
+func (p *parser) walk(b []byte, paths [][]interface{}, n *pathNode, set func(int, Json)) (int, error) {
+	n.seen = true
+	i := p.skipspace(b)
+	b = b[i:]
+	if len(b) == 0 {
+		return i, p.error(b, ErrJSONEOF)
+	}
+
+	if len(n.leaves) != 0 {
+		// the whole value is needed, paths under n are looked up from it.
+		j, ii, err := p.parse(b)
+		if err != nil {
+			return i, err
+		}
+		j.nm = p.opt.MatchNames
+		j.sp = p.opt.InvalidStrings
+		j.json5 = p.opt.JSON5
+		n.each(func(k int) {
+			set(k, lookup(j, paths[k][:n.depth], paths[k][n.depth:]))
+		})
+		return i + ii, nil
+	}
+
+	var ii int
+	var err error
+	switch b[0] {
+	case '{':
+		ii, err = p.walkObject(b, paths, n, set)
+	case '[':
+		ii, err = p.walkArray(b, paths, n, set)
+	default:
+		ii, err = p.jsonskip(b)
+		if err == nil {
+			ii, err = p.walkDone(ii, paths, n, set, typeOf(b[0]), nil, nil)
+		}
+	}
+	return i + ii, err
+}
+
+func (p *parser) walkObject(b []byte, paths [][]interface{}, n *pathNode, set func(int, Json)) (int, error) {
+	err := p.enter(b)
+	defer p.leave()
+	if err != nil {
+		return 0, err
+	}
+
+	const (
+		stateMemberName  = 1
+		stateColon       = 2
+		stateMemberValue = 3
+		stateDone        = 4
+	)
+	state := stateMemberName
+
+	var k []byte
+	var names []kv // collected for ParseMemberNamesOnly
+	members := 0
+	unique := 0 // members of different names
+	dup := -1
+	var seen keyset
+	each := newEachOf(n)
+
+	i := 1 // skip {
+	for i < len(b) {
+		if p.isspace(b[i]) {
+			i++
+			continue
+		}
+		if n := p.comment(b[i:]); n > 0 {
+			i += n
+			continue
+		}
+		if state == stateMemberName {
+			if b[i] == '}' && members == 0 {
+				return p.walkDone(i+1, paths, n, set, OBJECT, names, each)
+			}
+			s, ii, err := p.parseKey(b[i:])
+			if err != nil {
+				return i, err
+			}
+			if dup, err = p.duplicate(&seen, b[i:], s); err != nil {
+				return i, err
+			}
+			i += ii
+			k = s
+			state = stateColon
+			continue
+		}
+		if state == stateColon {
+			if b[i] != ':' {
+				return i, p.errorf(b[i:], "OBJECT: expect ':' found '%c'", b[i])
+			}
+			i++
+			state = stateMemberValue
+			continue
+		}
+		if state == stateMemberValue {
+			if err := p.member(b[i:], members); err != nil {
+				return i, err
+			}
+			members++
+			pos := dup
+			if dup < 0 {
+				pos = unique
+				unique++
+				if len(n.names) != 0 {
+					names = append(names, kv{k: k, v: Json{tp: NULL}})
+				}
+			}
+			lastwins := p.opt.Duplicates == DuplicateLastWins
+			var ii int
+			var err error
+			walked := false
+			name, kerr := p.name(k)
+			if each != nil && (dup < 0 || lastwins) {
+				ii, err = p.walkEach(b[i:], paths, each, pos, name)
+				walked = true
+			}
+			n.member(p.opt.MatchNames, name, func(c *pathNode) {
+				// as Member does, the first member matched is the one, or the last
+				// of its duplicates with DuplicateLastWins, duplicates are of exact names.
+				if err != nil || c.seen && (!lastwins || c.pos != pos) {
+					return
+				}
+				walked = true
+				if kerr != nil {
+					err = p.error(k, kerr)
+					return
+				}
+				c.reset()
+				c.pos = pos
+				ii, err = p.walk(b[i:], paths, c, set)
+			})
+			if !walked {
+				ii, err = p.jsonskip(b[i:])
+			}
+			if err != nil {
+				return i, within(err, k)
+			}
+			i += ii
+			state = stateDone
+			continue
+		}
+		if state == stateDone {
+			if b[i] == ',' {
+				i++
+				if p.trailingComma(b[i:], '}') {
+					continue // the end is handled in stateDone
+				}
+				state = stateMemberName
+				continue
+			}
+			if b[i] == '}' {
+				return p.walkDone(i+1, paths, n, set, OBJECT, names, each)
+			}
+			return i, p.errorf(b[i:], "OBJECT: expect ',' or '}' found '%c'", b[i])
+		}
+	}
+	return i, p.error(b[len(b):], ErrObjectEOF)
+}
+
+func (p *parser) walkArray(b []byte, paths [][]interface{}, n *pathNode, set func(int, Json)) (int, error) {
+	err := p.enter(b)
+	defer p.leave()
+	if err != nil {
+		return 0, err
+	}
+
+	const (
+		stateValue = 1
+		stateDone  = 2
+	)
+	state := stateValue
+
+	pos := 0
+	each := newEachOf(n)
+
+	i := 1 // skip [
+	for i < len(b) {
+		if p.isspace(b[i]) {
+			i++
+			continue
+		}
+		if n := p.comment(b[i:]); n > 0 {
+			i += n
+			continue
+		}
+		if state == stateValue {
+			if b[i] == ']' && pos == 0 {
+				return p.walkDone(i+1, paths, n, set, ARRAY, nil, each)
+			}
+			if err := p.element(b[i:], pos); err != nil {
+				return i, err
+			}
+			var ii int
+			var err error
+			if each != nil {
+				ii, err = p.walkEach(b[i:], paths, each, pos, pos)
+			}
+			if c := n.elements[pos]; err == nil && c != nil {
+				ii, err = p.walk(b[i:], paths, c, set)
+			} else if each == nil {
+				ii, err = p.jsonskip(b[i:])
+			}
+			if err != nil {
+				return i, within(err, pos)
+			}
+			i += ii
+			pos++
+			state = stateDone
+			continue
+		}
+		if state == stateDone {
+			if b[i] == ',' {
+				i++
+				if p.trailingComma(b[i:], ']') {
+					continue // the end is handled in stateDone
+				}
+				state = stateValue
+				continue
+			}
+			if b[i] == ']' {
+				return p.walkDone(i+1, paths, n, set, ARRAY, nil, each)
+			}
+			return i, p.errorf(b[i:], "ARRAY: expect ',' or ']' found '%c'", b[i])
+		}
+	}
+	return i, p.error(b[len(b):], ErrArrayEOF)
+}
+
+// walkDone sets the results of paths under n not found in the container of type t,
+// and the results collected by each.
+func (p *parser) walkDone(i int, paths [][]interface{}, n *pathNode, set func(int, Json), t Type, names []kv, each *eachOf) (int, error) {
+	null := Json{tp: NULL, missing: true}
+	mismatch := func(k int, expected Type) {
+		set(k, Json{err: withPath(Json{tp: t}.mismatch(expected), paths[k][:n.depth]...)})
+	}
+	if n.every != nil {
+		if t == OBJECT || t == ARRAY {
+			each.done(set)
+		} else {
+			j := Json{err: fmt.Errorf("type %s %w", t, ErrEachOf)}
+			n.every.each(func(k int) { set(k, j) })
+		}
+	}
+	for _, k := range n.names {
+		if t == OBJECT {
+			set(k, Json{tp: OBJECT, m: names, x: newMemberIndex(names)})
+		} else {
+			mismatch(k, OBJECT)
+		}
+	}
+	for _, c := range n.members {
+		if t == OBJECT {
+			if !c.seen {
+				c.each(func(k int) { set(k, null) })
+			}
+		} else {
+			c.each(func(k int) { mismatch(k, OBJECT) })
+		}
+	}
+	for _, c := range n.elements {
+		if t == ARRAY {
+			if !c.seen {
+				c.each(func(k int) { set(k, null) })
+			}
+		} else {
+			c.each(func(k int) { mismatch(k, ARRAY) })
+		}
+	}
+	return i, nil
+}
+
+// lookup returns the value of keys under j, which is the value of path,
+// as walk does: a value under a missing value is missing too,
+// and errors have the whole path.
+func lookup(j Json, path, keys []interface{}) Json {
+	at := func(i int) []interface{} {
+		return append(append(make([]interface{}, 0, len(path)+i), path...), keys[:i]...)
+	}
+	for i, k := range keys {
+		if j.missing {
+			return j
+		}
+		switch k {
+		case ParseMemberNamesOnly:
+			v := memberNamesOnly(j)
+			v.err = withPath(v.err, at(i)...)
+			return v
+		case Each:
+			return lookupEach(j, at(i), keys[i+1:])
+		}
+		j = j.Get(k)
+		if j.err != nil {
+			j.err = withPath(j.err, at(i)...)
+			return j
+		}
+	}
+	return j
+}
+
+// lookupEach is like j.EachOf(keys...), but looks up keys as lookup does.
+func lookupEach(j Json, path, keys []interface{}) Json {
+	if j.tp != OBJECT && j.tp != ARRAY {
+		return Json{err: fmt.Errorf("type %s %w", j.Type(), ErrEachOf)}
+	}
+	if err := j.load(); err != nil {
+		return Json{err: err}
+	}
+	n := len(j.a)
+	if j.tp == OBJECT {
+		n = len(j.m)
+	}
+	ret := Json{tp: ARRAY, a: make([]Json, n)}
+	for i := range ret.a {
+		var k interface{} = i
+		e := Json{}
+		if j.tp == OBJECT {
+			k = j.m[i].key(j.json5)
+			e = j.m[i].v
+		} else {
+			e = j.a[i]
+		}
+		v := lookup(j.returnj(e), append(path[:len(path):len(path)], k), keys)
+		if v.err != nil {
+			return v
+		}
+		ret.a[i] = v
+	}
+	return j.returnj(ret)
+}
+
+// memberNamesOnly returns an OBJECT with the member names of j and NULL values,
+// the same as parsing j with ParseMemberNamesOnly.
+func memberNamesOnly(j Json) Json {
+	if j.tp != OBJECT {
+		return Json{err: j.mismatch(OBJECT)}
+	}
+	if err := j.load(); err != nil {
+		return Json{err: err}
+	}
+	m := make([]kv, len(j.m))
+	for i := range j.m {
+		m[i] = kv{s: j.m[i].s, k: j.m[i].k, v: Json{tp: NULL}}
+	}
+	return Json{tp: OBJECT, m: m, x: newMemberIndex(m)}
+}
+
+// typeOf returns the Type of the value beginning with c.
+func typeOf(c byte) Type {
+	switch c {
+	case '{':
+		return OBJECT
+	case '[':
+		return ARRAY
+	case '"', '\'':
+		return STRING
+	case 't', 'f':
+		return BOOL
+	case 'n':
+		return NULL
+	}
+	return NUMBER
+}
@@ -0,0 +1,49 @@
+package jsonport
+
+import "strings"
+
+// A NameMatch tells how member names are matched by Member, Get, EachOf
+// and the keys of Unmarshal.
+type NameMatch uint8
+
+const (
+	// MatchExact matches member names byte by byte, it is the default.
+	MatchExact NameMatch = iota
+	// MatchFold matches member names case-insensitively by Unicode case folding,
+	// "userId" matches "UserID".
+	MatchFold
+	// MatchNormalized matches member names by case folding after removing '_' and '-',
+	// so that snake_case, kebab-case and camelCase names match each other:
+	// "userId" matches "UserID", "user_id" and "user-id".
+	MatchNormalized
+)
+
+// MatchNames sets how member names of j and the values got from it are matched.
+func (j *Json) MatchNames(m NameMatch) {
+	j.nm = m
+}
+
+// match reports whether the member name k matches name.
+func (m NameMatch) match(k, name string) bool {
+	switch m {
+	case MatchFold:
+		return strings.EqualFold(k, name)
+	case MatchNormalized:
+		return strings.EqualFold(normalizeName(k), normalizeName(name))
+	}
+	return k == name
+}
+
+// normalizeName removes '_' and '-' in name.
+func normalizeName(name string) string {
+	if strings.IndexByte(name, '_') < 0 && strings.IndexByte(name, '-') < 0 {
+		return name
+	}
+	b := make([]byte, 0, len(name))
+	for i := 0; i < len(name); i++ {
+		if c := name[i]; c != '_' && c != '-' {
+			b = append(b, c)
+		}
+	}
+	return string(b)
+}
@@ -2,7 +2,7 @@ package jsonport
 
 import (
 	"bytes"
-	"errors"
+	"context"
 	"fmt"
 	"strconv"
 	"sync"
@@ -15,8 +15,204 @@ var (
 	jsontrue  = []byte("true")
 	jsonfalse = []byte("false")
 	jsonnull  = []byte("null")
+
+	jsoninfinity = []byte("Infinity")
+	jsonnan      = []byte("NaN")
+
+	commentEnd = []byte("*/")
+)
+
+// Options configures how JSON text is parsed.
+// The zero value is the lenient parser used by Unmarshal and DecodeFrom.
+type Options struct {
+	// Strict rejects everything RFC 8259 forbids:
+	// malformed numbers and leading zeros, whitespace other than
+	// space, tab, LF and CR, raw control characters and unknown escapes
+	// in strings, and strings which are not valid UTF-8.
+	Strict bool
+
+	// Limits for parsing untrusted input, a LimitError is returned
+	// if any of them is exceeded. Zero means no limit.
+	MaxDepth     int // nesting depth of objects and arrays
+	MaxBytes     int // length of the input
+	MaxMembers   int // members of an object
+	MaxElements  int // elements of an array
+	MaxStringLen int // bytes of a string literal, without quotes
+
+	// Duplicates is the policy for members of the same name in an object.
+	Duplicates DuplicatePolicy
+
+	// InvalidStrings is how strings which can not be decoded are handled,
+	// and is set to the Json returned. The default is to report an error.
+	// Strict rejects them in parsing, except for lone surrogates.
+	InvalidStrings StringPolicy
+
+	// MatchNames is how member names in keys passed to Unmarshal and
+	// UnmarshalPaths are matched, and is set to the Json returned for Get.
+	MatchNames NameMatch
+
+	// Copy makes a copy of the input before parsing.
+	// By default strings, numbers and Raw() of the result refer to the input,
+	// so the input must not be modified or reused while any of them is in use.
+	// See also Json.Clone.
+	Copy bool
+
+	// JSON5 accepts the JSON5 extensions used in hand-written files like configs:
+	// comments, trailing commas, single-quoted strings, unquoted member names,
+	// hexadecimal numbers, numbers like +1, .5 and 5., Infinity and NaN.
+	// The result is the same as of the equivalent JSON, with Raw() as in the input.
+	JSON5 bool
+
+	// Lazy delays parsing of objects and arrays until they are accessed
+	// by Member, Element, Keys, Values, Array, Len or the like.
+	// They are only checked for syntax errors at first,
+	// and the parsed members or elements are cached once accessed.
+	Lazy bool
+}
+
+// A DuplicatePolicy tells how to handle members of the same name in an object.
+type DuplicatePolicy int
+
+const (
+	// DuplicateKeepAll keeps every member, Member returns the first one
+	// while Keys and Values return all of them. It is the default.
+	DuplicateKeepAll DuplicatePolicy = iota
+	// DuplicateFirstWins keeps the first member of the same name only.
+	DuplicateFirstWins
+	// DuplicateLastWins keeps the value of the last member of the same name,
+	// at the position of the first one.
+	DuplicateLastWins
+	// DuplicateReject fails with an error wrapping ErrDuplicateKey.
+	DuplicateReject
 )
 
+type parser struct {
+	opt Options
+
+	data []byte // the whole input, errors are located by offsets into it
+
+	ctx   context.Context // checked every ctxCheckInterval values if not nil
+	ticks int
+	depth int
+}
+
+const ctxCheckInterval = 1024
+
+func newParser(opt *Options, data []byte) *parser {
+	p := &parser{data: data}
+	if opt != nil {
+		p.opt = *opt
+	}
+	if p.opt.Copy {
+		p.data = append([]byte(nil), data...)
+	}
+	return p
+}
+
+// offset returns the offset of b in p.data, b must be sliced from p.data.
+func (p *parser) offset(b []byte) int {
+	return cap(p.data) - cap(b)
+}
+
+func (p *parser) error(b []byte, err error) error {
+	return newSyntaxError(p.data, p.offset(b), err)
+}
+
+func (p *parser) errorf(b []byte, format string, args ...interface{}) error {
+	return p.error(b, fmt.Errorf(format, args...))
+}
+
+func (p *parser) limit(b []byte, name string, max, n int) error {
+	if max > 0 && n > max {
+		return &LimitError{Limit: name, Max: max, Offset: int64(p.offset(b))}
+	}
+	return nil
+}
+
+// enter is called when parsing an object or array at b.
+// leave must be called after it is done, even if enter returns an error.
+func (p *parser) enter(b []byte) error {
+	p.depth++
+	return p.limit(b, "MaxDepth", p.opt.MaxDepth, p.depth)
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+// member is called before parsing the value of the nth member of an object.
+func (p *parser) member(b []byte, n int) error {
+	if err := p.limit(b, "MaxMembers", p.opt.MaxMembers, n+1); err != nil {
+		return err
+	}
+	return p.tick()
+}
+
+// element is called before parsing the nth element of an array.
+func (p *parser) element(b []byte, n int) error {
+	if err := p.limit(b, "MaxElements", p.opt.MaxElements, n+1); err != nil {
+		return err
+	}
+	return p.tick()
+}
+
+// keyset is the set of member names seen in an object.
+type keyset struct {
+	keys []string
+	m    map[string]int
+}
+
+// add adds the member name k,
+// it returns the index of k if it was added before or -1.
+func (s *keyset) add(k string) int {
+	if s.m != nil {
+		if i, ok := s.m[k]; ok {
+			return i
+		}
+		s.m[k] = len(s.keys)
+		s.keys = append(s.keys, k)
+		return -1
+	}
+	for i := range s.keys {
+		if s.keys[i] == k {
+			return i
+		}
+	}
+	s.keys = append(s.keys, k)
+	if len(s.keys) > 16 {
+		s.m = make(map[string]int, len(s.keys)*2)
+		for i, k := range s.keys {
+			s.m[k] = i
+		}
+	}
+	return -1
+}
+
+// duplicate checks the member name k at b for Options.Duplicates,
+// it returns the index of the member of the same name seen before or -1.
+func (p *parser) duplicate(keys *keyset, b []byte, k []byte) (int, error) {
+	if p.opt.Duplicates == DuplicateKeepAll {
+		return -1, nil
+	}
+	name := p.unquote(k)
+	i := keys.add(name)
+	if i >= 0 && p.opt.Duplicates == DuplicateReject {
+		return i, p.error(b, fmt.Errorf("OBJECT: %w %q", ErrDuplicateKey, name))
+	}
+	return i, nil
+}
+
+func (p *parser) tick() error {
+	if p.ctx == nil {
+		return nil
+	}
+	p.ticks++
+	if p.ticks%ctxCheckInterval != 0 {
+		return nil
+	}
+	return p.ctx.Err()
+}
+
 func isspace(b byte) bool {
 	switch b {
 	case ' ':
@@ -31,70 +227,141 @@ func isspace(b byte) bool {
 	return true
 }
 
-func skipspace(b []byte) int {
-	for i, c := range b {
-		if !isspace(c) {
-			return i
+// isspace reports whether b is whitespace, '\v' and '\f' are not in strict mode.
+func (p *parser) isspace(b byte) bool {
+	if p.opt.Strict && !p.opt.JSON5 && (b == '\v' || b == '\f') {
+		return false
+	}
+	return isspace(b)
+}
+
+func (p *parser) skipspace(b []byte) int {
+	i := 0
+	for i < len(b) {
+		c := b[i]
+		if p.isspace(c) {
+			i++
+			continue
+		}
+		if c == '/' && p.opt.JSON5 {
+			if n := comment(b[i:]); n > 0 {
+				i += n
+				continue
+			}
 		}
+		return i
 	}
 	return len(b)
 }
 
-func parse(b []byte) (Json, int, error) {
-	i := skipspace(b)
+// comment is like the function comment, but returns 0 if JSON5 is not enabled.
+func (p *parser) comment(b []byte) int {
+	if !p.opt.JSON5 || b[0] != '/' {
+		return 0
+	}
+	return comment(b)
+}
+
+// comment returns the length of the JSON5 comment at b,
+// or 0 if b does not begin with a complete comment.
+// A line comment may end at the end of b.
+func comment(b []byte) int {
+	if len(b) < 2 || b[0] != '/' {
+		return 0
+	}
+	switch b[1] {
+	case '/':
+		if n := bytes.IndexByte(b[2:], '\n'); n >= 0 {
+			return 2 + n + 1
+		}
+		return len(b)
+	case '*':
+		if n := bytes.Index(b[2:], commentEnd); n >= 0 {
+			return 2 + n + len(commentEnd)
+		}
+	}
+	return 0
+}
+
+// trailingComma reports whether b after a ',' is the end of the object or array
+// ending with end, which is allowed in JSON5.
+func (p *parser) trailingComma(b []byte, end byte) bool {
+	if !p.opt.JSON5 {
+		return false
+	}
+	n := p.skipspace(b)
+	return n < len(b) && b[n] == end
+}
+
+func (p *parser) parse(b []byte) (Json, int, error) {
+	i := p.skipspace(b)
 	b = b[i:]
 	if len(b) == 0 {
-		return Json{}, 0, errJSONEOF
+		return Json{}, 0, p.error(b[len(b):], ErrJSONEOF)
+	}
+
+	if p.opt.Lazy && (b[0] == '{' || b[0] == '[') {
+		j, ii, err := p.lazyValue(b, typeOf(b[0]))
+		if err != nil {
+			j.err = err
+			return j, i, err
+		}
+		return j, i + ii, nil
 	}
 
 	var j Json
-	switch b[0] {
+	switch p.first(b) {
 	case '{':
-		o, ii, err := parseObject(b, false)
+		o, ii, err := p.parseObject(b, false)
 		if err != nil {
 			j.err = err
 			return j, i, err
 		}
+		j.b = b[:ii]
 		i += ii
 		j.m = o
+		j.x = newMemberIndex(o)
 		j.tp = OBJECT
 	case '[':
-		a, ii, err := parseArray(b)
+		a, ii, err := p.parseArray(b)
 		if err != nil {
 			j.err = err
 			return j, i, err
 		}
+		j.b = b[:ii]
 		i += ii
 		j.a = a
 		j.tp = ARRAY
 	case '"':
-		s, ii, err := parseString(b)
+		_, ii, err := p.parseString(b)
 		if err != nil {
 			j.err = err
 			return j, i, err
 		}
+		j.b = b[:ii] // with quotes, see Json.str()
 		i += ii
-		j.b = s
 		j.tp = STRING
 	case 't', 'f':
-		tf, ii, err := parseBool(b)
+		tf, ii, err := p.parseBool(b)
 		if err != nil {
 			j.err = err
 			return j, i, err
 		}
+		j.b = b[:ii]
 		i += ii
 		j.t = tf
 		j.tp = BOOL
 	case 'n':
-		ii, err := parseNull(b)
+		ii, err := p.parseNull(b)
 		if err != nil {
 			j.err = err
 			return j, i, err
 		}
+		j.b = b[:ii]
 		i += ii
 		j.tp = NULL
 	default:
-		n, ii, err := parseNumber(b)
+		n, ii, err := p.parseNumber(b)
 		if err != nil {
 			j.err = err
 			return j, i, err
@@ -143,55 +410,98 @@ func parseArrayIndex(k interface{}) (int, error) {
 
 }
 
-func parsePath(b []byte, keys ...interface{}) (Json, int, error) {
+func (p *parser) parsePath(b []byte, keys ...interface{}) (Json, int, error) {
 	if len(keys) == 0 {
-		return parse(b)
+		return p.parse(b)
 	}
 
-	i := skipspace(b)
+	i := p.skipspace(b)
 	b = b[i:]
 	if len(b) == 0 {
-		return Json{}, i, errJSONEOF
+		return Json{}, i, p.error(b[len(b):], ErrJSONEOF)
 	}
 
 	if name, err := parseMemberName(keys[0]); err == nil {
 		if name == ParseMemberNamesOnly {
-			o, ii, err := parseObject(b, true)
+			o, ii, err := p.parseObject(b, true)
+			j := Json{m: o, x: newMemberIndex(o), tp: OBJECT, b: b[:ii]}
+			i += ii
+			return j, i, err
+		}
+		if name == Each {
+			j, ii, err := p.parseEach(b, keys[1:]...)
 			i += ii
-			j := Json{m: o, tp: OBJECT}
 			return j, i, err
 		}
-		j, ii, err := parseObjectMember(b, name, keys[1:]...)
+		j, ii, err := p.parseObjectMember(b, name, keys[1:]...)
 		i += ii
 		return j, i, err
 	}
 
 	if index, err := parseArrayIndex(keys[0]); err == nil {
-		j, ii, err := parseArrayElement(b, index, keys[1:]...)
+		j, ii, err := p.parseArrayElement(b, index, keys[1:]...)
 		i += ii
 		return j, i, err
 	} else {
-		return Json{}, 0, errors.New("key type error")
+		return Json{}, 0, ErrKeyType
 	}
 }
 
-func parseString(b []byte) ([]byte, int, error) {
-	if b[0] != '"' {
-		return nil, 0, fmt.Errorf("STRING: expect '\"' found '%c'", b[0])
+// first returns the first byte of the value at b,
+// a single quote of JSON5 is returned as the double quote.
+func (p *parser) first(b []byte) byte {
+	if b[0] == '\'' && p.opt.JSON5 {
+		return '"'
 	}
+	return b[0]
+}
+
+// parseKey parses a member name, the name is returned without quotes.
+// Member names in JSON5 may be identifiers without quotes.
+func (p *parser) parseKey(b []byte) ([]byte, int, error) {
+	if p.opt.JSON5 && isIdentStart(b[0]) {
+		i := 1
+		for i < len(b) && (isIdentStart(b[i]) || b[i] >= '0' && b[i] <= '9') {
+			i++
+		}
+		return b[:i], i, nil
+	}
+	return p.parseString(b)
+}
+
+// isIdentStart reports whether c may begin an unquoted member name of JSON5.
+// Bytes of non-ASCII characters are accepted without checking the characters.
+func isIdentStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == '$' || c >= utf8.RuneSelf
+}
+
+func (p *parser) parseString(b []byte) ([]byte, int, error) {
+	q := p.first(b)
+	if q != '"' {
+		return nil, 0, p.errorf(b, "STRING: expect '\"' found '%c'", b[0])
+	}
+	q = b[0]
 	var i int
 	var escaped bool
 	for i, c := range b[1:] {
 		if c == '\\' {
 			escaped = !escaped
-		} else if c == '"' && !escaped {
+		} else if c == q && !escaped {
 			s := b[1 : i+1] // trim "\""
+			if err := p.limit(b, "MaxStringLen", p.opt.MaxStringLen, len(s)); err != nil {
+				return nil, i + 2, err
+			}
+			if p.opt.Strict {
+				if n, err := checkString(s, p.opt.JSON5); err != nil {
+					return nil, i + 2, p.error(s[n:], err)
+				}
+			}
 			return s, i + 2, nil
 		} else {
 			escaped = false
 		}
 	}
-	return nil, i, errStringEOF
+	return nil, i, p.error(b[len(b):], ErrStringEOF)
 }
 
 type obj struct {
@@ -204,18 +514,23 @@ var opool = sync.Pool{
 	},
 }
 
-func parseObject(b []byte, namesonly bool) ([]kv, int, error) {
+func (p *parser) parseObject(b []byte, namesonly bool) ([]kv, int, error) {
 	if len(b) == 0 {
-		return nil, 0, errors.New("OBJECT: expect '{' found EOF")
+		return nil, 0, p.error(b[len(b):], ErrObjectEOF)
 	}
 	if b[0] != '{' {
-		return nil, 0, fmt.Errorf("OBJECT: expect '{' found '%c", b[0])
+		return nil, 0, p.errorf(b, "OBJECT: expect '{' found '%c'", b[0])
 	}
 	if len(b) < 2 {
-		return nil, 1, errors.New("OBJECT: expect '}' found EOF")
+		return nil, 1, p.error(b[len(b):], ErrObjectEOF)
+	}
+	err := p.enter(b)
+	defer p.leave()
+	if err != nil {
+		return nil, 0, err
 	}
-	if b[1] == '}' {
-		return nil, 2, nil
+	if n := 1 + p.skipspace(b[1:]); n < len(b) && b[n] == '}' {
+		return nil, n + 1, nil
 	}
 
 	const (
@@ -227,81 +542,106 @@ func parseObject(b []byte, namesonly bool) ([]kv, int, error) {
 	state := stateMemberName
 
 	var k []byte
+	dup := -1
+	var keys keyset
+	members := 0 // duplicates included, unlike len(o.kvs)
 
-	p := opool.Get().(*obj)
-	defer opool.Put(p)
-	p.kvs = p.kvs[:0]
+	o := opool.Get().(*obj)
+	defer opool.Put(o)
+	o.kvs = o.kvs[:0]
 
 	i := 1 // skip {
 	for i < len(b) {
-		if isspace(b[i]) {
+		if p.isspace(b[i]) {
 			i++
 			continue
 		}
+		if n := p.comment(b[i:]); n > 0 {
+			i += n
+			continue
+		}
 		if state == stateMemberName {
-			b, ii, err := parseString(b[i:])
+			s, ii, err := p.parseKey(b[i:])
 			if err != nil {
-				return nil, i, fmt.Errorf("OBJECT member.name: %s", err)
+				return nil, i, err
+			}
+			if dup, err = p.duplicate(&keys, b[i:], s); err != nil {
+				return nil, i, err
 			}
 			i += ii
-			k = b
+			k = s
 			state = stateColon
 			continue
 		}
 		if state == stateColon {
 			if b[i] != ':' {
-				return nil, i, fmt.Errorf("OBJECT: expect ':' found '%c'", b[i])
+				return nil, i, p.errorf(b[i:], "OBJECT: expect ':' found '%c'", b[i])
 			}
 			i++
 			state = stateMemberValue
 			continue
 		}
 		if state == stateMemberValue {
+			if err := p.member(b[i:], members); err != nil {
+				return nil, i, err
+			}
+			members++
 			j := Json{tp: NULL}
 			var ii int
-			var err error
-			if namesonly {
-				ii, err = jsonskip(b[i:])
+			if namesonly || (dup >= 0 && p.opt.Duplicates == DuplicateFirstWins) {
+				ii, err = p.jsonskip(b[i:])
 			} else {
-				j, ii, err = parse(b[i:])
-				if err != nil {
-					return nil, i, fmt.Errorf("OBJECT: member %q parse err: %s", k, err)
-				}
+				j, ii, err = p.parse(b[i:])
+			}
+			if err != nil {
+				return nil, i, within(err, k)
 			}
 			i += ii
-			p.kvs = append(p.kvs, kv{k: k, v: j})
+			if dup < 0 {
+				o.kvs = append(o.kvs, kv{k: k, v: j})
+			} else if p.opt.Duplicates == DuplicateLastWins {
+				o.kvs[dup].v = j
+			}
 			state = stateDone
 			continue
 		}
 		if state == stateDone {
 			if b[i] == ',' {
 				i++
+				if p.trailingComma(b[i:], '}') {
+					continue // the end is handled in stateDone
+				}
 				state = stateMemberName
 				continue
 			}
 			if b[i] == '}' {
 				i++
-				m := make([]kv, 0, len(p.kvs))
-				return append(m, p.kvs...), i, nil
+				m := make([]kv, 0, len(o.kvs))
+				return append(m, o.kvs...), i, nil
 			}
-			return nil, i, fmt.Errorf("OBJECT: expect ',' or '}' found '%c'", b[i])
+			return nil, i, p.errorf(b[i:], "OBJECT: expect ',' or '}' found '%c'", b[i])
 		}
 	}
-	return nil, i, errors.New("OBJECT: internal err")
+	return nil, i, p.error(b[len(b):], ErrObjectEOF)
 }
 
-func parseArray(b []byte) ([]Json, int, error) {
+func (p *parser) parseArray(b []byte) ([]Json, int, error) {
 	if len(b) == 0 {
-		return nil, 0, errors.New("ARRAY: expect '[' found EOF")
+		return nil, 0, p.error(b[len(b):], ErrArrayEOF)
 	}
 	if b[0] != '[' {
-		return nil, 0, fmt.Errorf("ARRAY: expect '[' found '%c'", b[0])
+		return nil, 0, p.errorf(b, "ARRAY: expect '[' found '%c'", b[0])
 	}
 	if len(b) < 2 {
-		return nil, 1, errors.New("ARRAY: expect ']' found EOF")
+		return nil, 1, p.error(b[len(b):], ErrArrayEOF)
+	}
+	err := p.enter(b)
+	defer p.leave()
+	if err != nil {
+		return nil, 0, err
 	}
-	if b[1] == ']' {
-		return []Json{}, 2, nil
+	if n := 1 + p.skipspace(b[1:]); n < len(b) && b[n] == ']' {
+		return []Json{}, n + 1, nil
 	}
 
 	const (
@@ -314,14 +654,21 @@ func parseArray(b []byte) ([]Json, int, error) {
 
 	i := 1 // skip [
 	for i < len(b) {
-		if isspace(b[i]) {
+		if p.isspace(b[i]) {
 			i++
 			continue
 		}
+		if n := p.comment(b[i:]); n > 0 {
+			i += n
+			continue
+		}
 		if state == stateValue {
-			j, ii, err := parse(b[i:])
+			if err := p.element(b[i:], len(a)); err != nil {
+				return nil, i, err
+			}
+			j, ii, err := p.parse(b[i:])
 			if err != nil {
-				return a, i, fmt.Errorf("ARRAY: index %d value: %s", len(a), err)
+				return a, i, within(err, len(a))
 			}
 			i += ii
 			a = append(a, j)
@@ -332,6 +679,9 @@ func parseArray(b []byte) ([]Json, int, error) {
 		if state == stateDone {
 			if b[i] == ',' {
 				i++
+				if p.trailingComma(b[i:], ']') {
+					continue // the end is handled in stateDone
+				}
 				state = stateValue
 				continue
 			}
@@ -339,19 +689,22 @@ func parseArray(b []byte) ([]Json, int, error) {
 				i++
 				return a, i, nil
 			}
-			return nil, i, fmt.Errorf("ARRAY: expect ',' or ']' found '%c'", b[i])
+			return nil, i, p.errorf(b[i:], "ARRAY: expect ',' or ']' found '%c'", b[i])
 		}
 	}
-	return nil, i, errArrayEOF
+	return nil, i, p.error(b[len(b):], ErrArrayEOF)
 }
 
-func parseNumber(b []byte) ([]byte, int, error) {
+func (p *parser) parseNumber(b []byte) ([]byte, int, error) {
 	if len(b) == 0 {
-		return nil, 0, errJSONEOF
+		return nil, 0, p.error(b[len(b):], ErrJSONEOF)
 	}
 	c := b[0]
+	if p.opt.JSON5 {
+		return p.parseNumber5(b)
+	}
 	if c != '-' && (c < '0' || c > '9') {
-		return nil, 0, errors.New("Unknown type")
+		return nil, 0, p.errorf(b, "JSON: invalid character '%c'", c)
 	}
 	var i int
 	for ; i < len(b); i++ {
@@ -364,41 +717,248 @@ func parseNumber(b []byte) ([]byte, int, error) {
 		case c == '+':
 		case c == '-':
 		default:
-			return b[:i], i, nil
+			return p.checkNumber(b, i)
+		}
+	}
+	return p.checkNumber(b, i)
+}
+
+// parseNumber5 parses a number of JSON5, which may be hexadecimal like 0x1F,
+// Infinity, NaN, or begin with '+' or '.' or end with '.'.
+func (p *parser) parseNumber5(b []byte) ([]byte, int, error) {
+	i := 0
+	if b[0] == '+' || b[0] == '-' {
+		i++
+	}
+	s := b[i:]
+	for _, lit := range [][]byte{jsoninfinity, jsonnan} {
+		if bytes.HasPrefix(s, lit) {
+			return b[:i+len(lit)], i + len(lit), nil
+		}
+		if len(s) > 0 && bytes.HasPrefix(lit, s) {
+			return nil, len(b), p.error(b[len(b):], ErrJSONEOF)
+		}
+	}
+	if len(s) > 1 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		n := 2
+		for n < len(s) && unhex(s[n]) >= 0 {
+			n++
+		}
+		if n == 2 {
+			if n == len(s) {
+				return nil, len(b), p.error(b[len(b):], ErrJSONEOF)
+			}
+			return nil, 0, p.errorf(b, "NUMBER: invalid literal %q", b[:i+n])
+		}
+		return b[:i+n], i + n, nil
+	}
+	if len(s) == 0 {
+		return nil, len(b), p.error(b[len(b):], ErrJSONEOF)
+	}
+	if c := s[0]; c != '.' && (c < '0' || c > '9') {
+		return nil, 0, p.errorf(b, "JSON: invalid character '%c'", c)
+	}
+	for ; i < len(b); i++ {
+		c := b[i]
+		switch {
+		case c >= '0' && c <= '9':
+		case c == '.':
+		case c == 'e':
+		case c == 'E':
+		case c == '+':
+		case c == '-':
+		default:
+			return p.checkNumber(b, i)
+		}
+	}
+	return p.checkNumber(b, i)
+}
+
+func (p *parser) checkNumber(b []byte, i int) ([]byte, int, error) {
+	if p.opt.Strict && p.opt.JSON5 {
+		if !isNumber5(b[:i]) {
+			if i == len(b) { // may be truncated like `1e`
+				return nil, i, p.error(b[i:], ErrJSONEOF)
+			}
+			return nil, 0, p.errorf(b, "NUMBER: invalid literal %q", b[:i])
 		}
+		return b[:i], i, nil
+	}
+	if p.opt.Strict && !isNumber(b[:i]) {
+		if i == len(b) { // may be truncated like `1e`
+			return nil, i, p.error(b[i:], ErrJSONEOF)
+		}
+		return nil, 0, p.errorf(b, "NUMBER: invalid literal %q", b[:i])
 	}
 	return b[:i], i, nil
 }
 
-func parseBool(b []byte) (bool, int, error) {
+// isNumber reports whether n matches the number grammar of RFC 8259:
+//
+//	number = [ minus ] int [ frac ] [ exp ]
+func isNumber(n []byte) bool {
+	i := 0
+	if i < len(n) && n[i] == '-' {
+		i++
+	}
+	// int = zero / ( digit1-9 *DIGIT )
+	switch {
+	case i < len(n) && n[i] == '0':
+		i++
+	case i < len(n) && n[i] >= '1' && n[i] <= '9':
+		i += digits(n[i:])
+	default:
+		return false
+	}
+	// frac = decimal-point 1*DIGIT
+	if i < len(n) && n[i] == '.' {
+		i++
+		d := digits(n[i:])
+		if d == 0 {
+			return false
+		}
+		i += d
+	}
+	// exp = e [ minus / plus ] 1*DIGIT
+	if i < len(n) && (n[i] == 'e' || n[i] == 'E') {
+		i++
+		if i < len(n) && (n[i] == '-' || n[i] == '+') {
+			i++
+		}
+		d := digits(n[i:])
+		if d == 0 {
+			return false
+		}
+		i += d
+	}
+	return i == len(n)
+}
+
+// isNumber5 reports whether n is a decimal number of JSON5, where the
+// integer part may have leading zeros or be omitted like .5, and the
+// fraction part may be omitted like 5.
+func isNumber5(n []byte) bool {
+	i := 0
+	if i < len(n) && (n[i] == '-' || n[i] == '+') {
+		i++
+	}
+	d := digits(n[i:])
+	i += d
+	if i < len(n) && n[i] == '.' {
+		i++
+		f := digits(n[i:])
+		d += f
+		i += f
+	}
+	if d == 0 {
+		return false
+	}
+	if i < len(n) && (n[i] == 'e' || n[i] == 'E') {
+		i++
+		if i < len(n) && (n[i] == '-' || n[i] == '+') {
+			i++
+		}
+		d := digits(n[i:])
+		if d == 0 {
+			return false
+		}
+		i += d
+	}
+	return i == len(n)
+}
+
+func digits(b []byte) int {
+	for i, c := range b {
+		if c < '0' || c > '9' {
+			return i
+		}
+	}
+	return len(b)
+}
+
+// checkString validates the content of a string literal without quotes,
+// rejecting raw control characters, unknown escapes and invalid UTF-8.
+// The offset of the invalid byte is returned with the error.
+func checkString(s []byte, json5 bool) (int, error) {
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c < ' ':
+			return i, fmt.Errorf("STRING: %w: control character 0x%02x", ErrInvalidString, c)
+		case c == '\\':
+			if i+1 >= len(s) {
+				return i, ErrStringEOF
+			}
+			if json5 {
+				if n := escape5(s[i:]); n > 0 {
+					i += n
+					continue
+				}
+			}
+			switch s[i+1] {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+				i += 2
+			case 'u':
+				if getu4(s[i:]) < 0 {
+					return i, fmt.Errorf("STRING: %w: invalid \\u escape", ErrInvalidString)
+				}
+				i += 6
+			default:
+				return i, fmt.Errorf("STRING: %w: invalid escape '\\%c'", ErrInvalidString, s[i+1])
+			}
+		case c < utf8.RuneSelf:
+			i++
+		default:
+			r, size := utf8.DecodeRune(s[i:])
+			if r == utf8.RuneError && size == 1 {
+				return i, fmt.Errorf("STRING: %w", ErrInvalidUTF8)
+			}
+			i += size
+		}
+	}
+	return 0, nil
+}
+
+func (p *parser) parseBool(b []byte) (bool, int, error) {
 	if bytes.HasPrefix(b, jsontrue) {
 		return true, len(jsontrue), nil
 	}
 	if bytes.HasPrefix(b, jsonfalse) {
 		return false, len(jsonfalse), nil
 	}
-	return false, 0, errors.New("BOOL: not true nor false")
+	if bytes.HasPrefix(jsontrue, b) || bytes.HasPrefix(jsonfalse, b) {
+		return false, len(b), p.error(b[len(b):], ErrJSONEOF)
+	}
+	return false, 0, p.errorf(b, "BOOL: not true nor false")
 }
 
-func parseNull(b []byte) (int, error) {
+func (p *parser) parseNull(b []byte) (int, error) {
 	if bytes.HasPrefix(b, jsonnull) {
 		return len(jsonnull), nil
 	}
-	return 0, errors.New("NULL: parse err")
+	if bytes.HasPrefix(jsonnull, b) {
+		return len(b), p.error(b[len(b):], ErrJSONEOF)
+	}
+	return 0, p.errorf(b, "NULL: parse err")
 }
 
-func parseObjectMember(b []byte, name string, keys ...interface{}) (Json, int, error) {
+func (p *parser) parseObjectMember(b []byte, name string, keys ...interface{}) (Json, int, error) {
 	if len(b) == 0 {
-		return Json{}, 0, errors.New("OBJECT: expect '{' found EOF")
+		return Json{}, 0, p.error(b[len(b):], ErrObjectEOF)
 	}
 	if b[0] != '{' {
-		return Json{}, 0, fmt.Errorf("OBJECT: expect '{' found '%c", b[0])
+		return Json{}, 0, p.errorf(b, "OBJECT: expect '{' found '%c'", b[0])
 	}
 	if len(b) < 2 {
-		return Json{}, 1, errors.New("OBJECT: expect '}' found EOF")
+		return Json{}, 1, p.error(b[len(b):], ErrObjectEOF)
 	}
-	if b[1] == '}' {
-		return Json{tp: NULL}, 2, nil
+	err := p.enter(b)
+	defer p.leave()
+	if err != nil {
+		return Json{}, 0, err
+	}
+	if n := 1 + p.skipspace(b[1:]); n < len(b) && b[n] == '}' {
+		return Json{tp: NULL, missing: true}, n + 1, nil
 	}
 
 	const (
@@ -410,26 +970,45 @@ func parseObjectMember(b []byte, name string, keys ...interface{}) (Json, int, e
 	state := stateMemberName
 
 	var k string
+	var ks []byte  // k in the input
+	var kerr error // k is invalid, see StringPolicy
+	ki := -1       // index of k in seen
+	members := 0
+	var seen keyset
+	found := -1 // offset of the value found, for DuplicateLastWins and DuplicateReject
+	first := -1 // index in seen of the first member matched, its duplicates may win
 
 	i := 1 // skip {
 	for i < len(b) {
-		if isspace(b[i]) {
+		if p.isspace(b[i]) {
 			i++
 			continue
 		}
+		if n := p.comment(b[i:]); n > 0 {
+			i += n
+			continue
+		}
 		if state == stateMemberName {
-			s, ii, err := parseString(b[i:])
+			s, ii, err := p.parseKey(b[i:])
+			if err != nil {
+				return Json{}, i, err
+			}
+			dup, err := p.duplicate(&seen, b[i:], s)
 			if err != nil {
-				return Json{}, i, fmt.Errorf("OBJECT member.name: %s", err)
+				return Json{}, i, err
+			}
+			if ki = dup; dup < 0 {
+				ki = len(seen.keys) - 1
 			}
 			i += ii
-			k = unquote(s)
+			ks = s
+			k, kerr = p.name(s)
 			state = stateColon
 			continue
 		}
 		if state == stateColon {
 			if b[i] != ':' {
-				return Json{}, i, fmt.Errorf("OBJECT: expect ':' found '%c'", b[i])
+				return Json{}, i, p.errorf(b[i:], "OBJECT: expect ':' found '%c'", b[i])
 			}
 			i++
 			state = stateMemberValue
@@ -437,55 +1016,85 @@ func parseObjectMember(b []byte, name string, keys ...interface{}) (Json, int, e
 		}
 
 		if state == stateMemberValue {
-			if k == name {
-				j, ii, err := parsePath(b[i:], keys...)
-				if err != nil {
-					return j, i, fmt.Errorf("OBJECT member %q parse err: %s", k, err)
+			if err := p.member(b[i:], members); err != nil {
+				return Json{}, i, err
+			}
+			members++
+			// as Member does, the first member matched is the one, or the last
+			// of its duplicates with DuplicateLastWins, duplicates are of exact names.
+			if (k == name || p.opt.MatchNames != MatchExact && p.opt.MatchNames.match(k, name)) &&
+				(found < 0 || p.opt.Duplicates == DuplicateLastWins && ki == first) {
+				if kerr != nil {
+					return Json{}, i, p.error(ks, kerr)
 				}
-				return j, i + ii, nil
-			} else {
-				ii, err := jsonskip(b[i:])
-				if err != nil {
-					return Json{}, i, fmt.Errorf("OBJECT member: %q parse err: %s", k, err)
+				switch p.opt.Duplicates {
+				case DuplicateKeepAll, DuplicateFirstWins:
+					j, ii, err := p.parsePath(b[i:], keys...)
+					if err != nil {
+						return j, i, within(err, k)
+					}
+					return j, i + ii, nil
+				default: // the rest of the object is checked for duplicates
+					found = i
+					first = ki
 				}
-				i += ii
-				state = stateDone
 			}
+			ii, err := p.jsonskip(b[i:])
+			if err != nil {
+				return Json{}, i, within(err, k)
+			}
+			i += ii
+			state = stateDone
 			continue
 		}
 
 		if state == stateDone {
 			if b[i] == ',' {
 				i++
+				if p.trailingComma(b[i:], '}') {
+					continue // the end is handled in stateDone
+				}
 				state = stateMemberName
 				continue
 			}
 			if b[i] == '}' {
 				i++
-				return Json{tp: NULL}, i, nil
+				if found >= 0 {
+					j, _, err := p.parsePath(b[found:], keys...)
+					if err != nil {
+						return j, found, within(err, name)
+					}
+					return j, i, nil
+				}
+				return Json{tp: NULL, missing: true}, i, nil
 			}
-			return Json{}, i, fmt.Errorf("OBJECT: expect ',' or '}' found '%c'", b[i])
+			return Json{}, i, p.errorf(b[i:], "OBJECT: expect ',' or '}' found '%c'", b[i])
 		}
 	}
-	return Json{}, i, errObjectEOF
+	return Json{}, i, p.error(b[len(b):], ErrObjectEOF)
 }
 
-func parseArrayElement(b []byte, index int, keys ...interface{}) (Json, int, error) {
+func (p *parser) parseArrayElement(b []byte, index int, keys ...interface{}) (Json, int, error) {
 	if len(b) == 0 {
-		return Json{}, 0, errors.New("ARRAY: expect '[' found EOF")
+		return Json{}, 0, p.error(b[len(b):], ErrArrayEOF)
 	}
 	if b[0] != '[' {
-		return Json{}, 0, fmt.Errorf("ARRAY: expect '[' found '%c'", b[0])
+		return Json{}, 0, p.errorf(b, "ARRAY: expect '[' found '%c'", b[0])
 	}
 	if len(b) < 2 {
-		return Json{}, 1, errors.New("ARRAY: expect ']' found EOF")
+		return Json{}, 1, p.error(b[len(b):], ErrArrayEOF)
+	}
+	err := p.enter(b)
+	defer p.leave()
+	if err != nil {
+		return Json{}, 0, err
 	}
-	if b[1] == ']' {
-		return Json{}, 2, nil
+	if n := 1 + p.skipspace(b[1:]); n < len(b) && b[n] == ']' {
+		return Json{tp: NULL, missing: true}, n + 1, nil
 	}
 
 	if index < 0 {
-		return Json{tp: NULL}, 0, nil
+		return Json{tp: NULL, missing: true}, 0, nil
 	}
 
 	const (
@@ -498,21 +1107,28 @@ func parseArrayElement(b []byte, index int, keys ...interface{}) (Json, int, err
 
 	i := 1 // skip [
 	for i < len(b) {
-		if isspace(b[i]) {
+		if p.isspace(b[i]) {
 			i++
 			continue
 		}
+		if n := p.comment(b[i:]); n > 0 {
+			i += n
+			continue
+		}
 		if state == stateValue {
+			if err := p.element(b[i:], pos); err != nil {
+				return Json{}, i, err
+			}
 			if pos != index {
-				ii, err := jsonskip(b[i:])
+				ii, err := p.jsonskip(b[i:])
 				if err != nil {
-					return Json{}, i, fmt.Errorf("ARRAY: index %d err: %s", pos, err)
+					return Json{}, i, within(err, pos)
 				}
 				i += ii
 			} else {
-				j, ii, err := parsePath(b[i:], keys...)
+				j, ii, err := p.parsePath(b[i:], keys...)
 				if err != nil {
-					return Json{}, i, fmt.Errorf("ARRAY: index %d err: %s", pos, err)
+					return Json{}, i, within(err, pos)
 				}
 				return j, i + ii, nil
 			}
@@ -524,29 +1140,221 @@ func parseArrayElement(b []byte, index int, keys ...interface{}) (Json, int, err
 		if state == stateDone {
 			if b[i] == ',' {
 				i++
+				if p.trailingComma(b[i:], ']') {
+					continue // the end is handled in stateDone
+				}
 				state = stateValue
 				continue
 			}
 			if b[i] == ']' {
 				i++
-				return Json{tp: NULL}, i, nil
+				return Json{tp: NULL, missing: true}, i, nil
 			}
-			return Json{}, i, fmt.Errorf("ARRAY: expect ',' or ']' found '%c'", b[i])
+			return Json{}, i, p.errorf(b[i:], "ARRAY: expect ',' or ']' found '%c'", b[i])
 		}
 	}
-	return Json{}, i, errArrayEOF
+	return Json{}, i, p.error(b[len(b):], ErrArrayEOF)
+}
+
+// parseEach parses the value specified by keys of every element of an array,
+// or of every member of an object, and returns them as an ARRAY.
+func (p *parser) parseEach(b []byte, keys ...interface{}) (Json, int, error) {
+	var end byte
+	switch b[0] {
+	case '{':
+		end = '}'
+	case '[':
+		end = ']'
+	default:
+		return Json{}, 0, p.errorf(b, "EACH: expect '{' or '[' found '%c': %w", b[0], ErrEachOf)
+	}
+	isObject := end == '}'
+	if len(b) < 2 {
+		if isObject {
+			return Json{}, 1, p.error(b[len(b):], ErrObjectEOF)
+		}
+		return Json{}, 1, p.error(b[len(b):], ErrArrayEOF)
+	}
+	err := p.enter(b)
+	defer p.leave()
+	if err != nil {
+		return Json{}, 0, err
+	}
+	if n := 1 + p.skipspace(b[1:]); n < len(b) && b[n] == end {
+		return Json{tp: ARRAY, a: []Json{}}, n + 1, nil
+	}
+
+	const (
+		stateMemberName  = 1
+		stateColon       = 2
+		stateMemberValue = 3
+		stateDone        = 4
+	)
+	state := stateMemberValue
+	if isObject {
+		state = stateMemberName
+	}
+
+	var k []byte
+	var a []Json
+	n := 0 // members or elements
+	dup := -1
+	var seen keyset
+
+	i := 1 // skip { or [
+	for i < len(b) {
+		if p.isspace(b[i]) {
+			i++
+			continue
+		}
+		if n := p.comment(b[i:]); n > 0 {
+			i += n
+			continue
+		}
+		if state == stateMemberName {
+			s, ii, err := p.parseKey(b[i:])
+			if err != nil {
+				return Json{}, i, err
+			}
+			if dup, err = p.duplicate(&seen, b[i:], s); err != nil {
+				return Json{}, i, err
+			}
+			i += ii
+			k = s
+			state = stateColon
+			continue
+		}
+		if state == stateColon {
+			if b[i] != ':' {
+				return Json{}, i, p.errorf(b[i:], "OBJECT: expect ':' found '%c'", b[i])
+			}
+			i++
+			state = stateMemberValue
+			continue
+		}
+		if state == stateMemberValue {
+			var err error
+			if isObject {
+				err = p.member(b[i:], n)
+			} else {
+				err = p.element(b[i:], n)
+			}
+			if err != nil {
+				return Json{}, i, err
+			}
+			n++
+			// parsePath returns once the value is found, skip to the end of it first.
+			ii, err := p.jsonskip(b[i:])
+			var j Json
+			if err == nil && !(dup >= 0 && p.opt.Duplicates == DuplicateFirstWins) {
+				j, _, err = p.parsePath(b[i:], keys...)
+			}
+			if err != nil {
+				if isObject {
+					return Json{}, i, within(err, k)
+				}
+				return Json{}, i, within(err, len(a))
+			}
+			i += ii
+			if dup < 0 {
+				a = append(a, j)
+			} else if p.opt.Duplicates == DuplicateLastWins {
+				a[dup] = j
+			}
+			state = stateDone
+			continue
+		}
+		if state == stateDone {
+			if b[i] == ',' {
+				i++
+				if p.trailingComma(b[i:], end) {
+					continue // the end is handled in stateDone
+				}
+				if isObject {
+					state = stateMemberName
+				} else {
+					state = stateMemberValue
+				}
+				continue
+			}
+			if b[i] == end {
+				i++
+				return Json{tp: ARRAY, a: a}, i, nil
+			}
+			return Json{}, i, p.errorf(b[i:], "EACH: expect ',' or '%c' found '%c'", end, b[i])
+		}
+	}
+	if isObject {
+		return Json{}, i, p.error(b[len(b):], ErrObjectEOF)
+	}
+	return Json{}, i, p.error(b[len(b):], ErrArrayEOF)
+}
+
+// escape5 returns the length of the escape sequence only in JSON5 at s,
+// like \' and \x41, or 0 if there is none.
+func escape5(s []byte) int {
+	if len(s) < 2 || s[0] != '\\' {
+		return 0
+	}
+	switch s[1] {
+	case '\'', 'v', '\n', '\r':
+		if s[1] == '\r' && len(s) > 2 && s[2] == '\n' {
+			return 3
+		}
+		return 2
+	case '0':
+		if len(s) > 2 && s[2] >= '0' && s[2] <= '9' {
+			return 0
+		}
+		return 2
+	case 'x':
+		if len(s) > 3 && unhex(s[2]) >= 0 && unhex(s[3]) >= 0 {
+			return 4
+		}
+	}
+	return 0
+}
+
+func unhex(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'a' && c <= 'f':
+		return int(c - 'a' + 10)
+	case c >= 'A' && c <= 'F':
+		return int(c - 'A' + 10)
+	}
+	return -1
+}
+
+// unquote decodes the content of a string literal, replacing everything invalid
+// with U+FFFD and accepting the escapes of JSON5. It is for the paths of errors.
+func unquote(s []byte) string {
+	ret, _ := StringReplace.unquote(s, true)
+	return ret
+}
+
+// unquote is like the function unquote, but accepts the escapes of JSON5
+// only if JSON5 is enabled.
+func (p *parser) unquote(s []byte) string {
+	ret, _ := StringReplace.unquote(s, p.opt.JSON5)
+	return ret
 }
 
 // unquote converts a quoted JSON string literal s into an actual string t.
 // The rules are different than for Go, so cannot use strconv.Unquote.
-func unquote(s []byte) string {
+// The invalid escapes, lone surrogates, control characters
+// and bytes of invalid UTF-8 in s are handled by sp.
+// The escapes of JSON5 like \x41 are invalid unless json5 is true,
+// except \' which is accepted without JSON5 as well, only Strict rejects it.
+func (sp StringPolicy) unquote(s []byte, json5 bool) (string, error) {
 	// Check for unusual characters. If there are none,
 	// then no unquoting is needed, so return a slice of the
 	// original bytes.
 	r := 0
 	for r < len(s) {
 		c := s[r]
-		if c == '\\' || c == '"' || c < ' ' {
+		if c == '\\' || c < ' ' {
 			break
 		}
 		if c < utf8.RuneSelf {
@@ -560,9 +1368,24 @@ func unquote(s []byte) string {
 		r += size
 	}
 	if r == len(s) {
-		return ss(s)
+		return ss(s), nil
 	}
 
+	// invalid handles the n bytes at r by sp.
+	invalid := func(b []byte, w, n int, err error) ([]byte, int, error) {
+		switch sp {
+		case StringReplace:
+			w += utf8.EncodeRune(b[w:], unicode.ReplacementChar)
+		case StringRaw:
+			w += copy(b[w:], s[r:r+n])
+		default:
+			return b, w, err
+		}
+		r += n
+		return b, w, nil
+	}
+	var err error
+
 	b := make([]byte, len(s)+2*utf8.UTFMax)
 	w := copy(b, s[0:r])
 	for r < len(s) {
@@ -576,61 +1399,85 @@ func unquote(s []byte) string {
 		}
 		switch c := s[r]; {
 		case c == '\\':
-			r++
-			if r >= len(s) {
-				return ""
+			if r+1 >= len(s) {
+				b, w, err = invalid(b, w, 1, fmt.Errorf("%w: unterminated escape at %d", ErrInvalidString, r))
+				break
 			}
-			switch s[r] {
+			if !json5 && s[r+1] != '\'' && escape5(s[r:]) > 0 {
+				b, w, err = invalid(b, w, 2, fmt.Errorf("%w: JSON5 escape '\\%c' at %d", ErrInvalidString, s[r+1], r))
+				break
+			}
+			switch s[r+1] {
 			default:
-				return ""
+				b, w, err = invalid(b, w, 2, fmt.Errorf("%w: invalid escape '\\%c' at %d", ErrInvalidString, s[r+1], r))
 			case '"', '\\', '/', '\'':
-				b[w] = s[r]
-				r++
+				b[w] = s[r+1]
+				r += 2
 				w++
 			case 'b':
 				b[w] = '\b'
-				r++
+				r += 2
 				w++
 			case 'f':
 				b[w] = '\f'
-				r++
+				r += 2
 				w++
 			case 'n':
 				b[w] = '\n'
-				r++
+				r += 2
 				w++
 			case 'r':
 				b[w] = '\r'
-				r++
+				r += 2
 				w++
 			case 't':
 				b[w] = '\t'
-				r++
+				r += 2
+				w++
+			case 'v': // JSON5
+				b[w] = '\v'
+				r += 2
+				w++
+			case '0': // JSON5
+				b[w] = 0
+				r += 2
 				w++
+			case 'x': // JSON5
+				if r+3 >= len(s) || unhex(s[r+2]) < 0 || unhex(s[r+3]) < 0 {
+					b, w, err = invalid(b, w, 2, fmt.Errorf("%w: invalid \\x escape at %d", ErrInvalidString, r))
+					break
+				}
+				w += utf8.EncodeRune(b[w:], rune(unhex(s[r+2])<<4|unhex(s[r+3])))
+				r += 4
+			case '\r', '\n': // JSON5 line continuation
+				if s[r+1] == '\r' && r+2 < len(s) && s[r+2] == '\n' {
+					r++
+				}
+				r += 2
 			case 'u':
-				r--
 				rr := getu4(s[r:])
 				if rr < 0 {
-					return ""
+					b, w, err = invalid(b, w, 2, fmt.Errorf("%w: invalid \\u escape at %d", ErrInvalidString, r))
+					break
 				}
-				r += 6
 				if utf16.IsSurrogate(rr) {
-					rr1 := getu4(s[r:])
+					rr1 := getu4(s[r+6:])
 					if dec := utf16.DecodeRune(rr, rr1); dec != unicode.ReplacementChar {
 						// A valid pair; consume.
-						r += 6
+						r += 12
 						w += utf8.EncodeRune(b[w:], dec)
 						break
 					}
-					// Invalid surrogate; fall back to replacement rune.
-					rr = unicode.ReplacementChar
+					b, w, err = invalid(b, w, 6, fmt.Errorf("%w: lone surrogate %s at %d", ErrInvalidString, s[r:r+6], r))
+					break
 				}
+				r += 6
 				w += utf8.EncodeRune(b[w:], rr)
 			}
 
-		// Quote, control characters are invalid.
-		case c == '"', c < ' ':
-			return ""
+		// Control characters are invalid.
+		case c < ' ':
+			b, w, err = invalid(b, w, 1, fmt.Errorf("%w: control character 0x%02x at %d", ErrInvalidString, c, r))
 
 		// ASCII
 		case c < utf8.RuneSelf:
@@ -638,14 +1485,20 @@ func unquote(s []byte) string {
 			r++
 			w++
 
-		// Coerce to well-formed UTF-8.
 		default:
 			rr, size := utf8.DecodeRune(s[r:])
+			if rr == utf8.RuneError && size == 1 {
+				b, w, err = invalid(b, w, 1, fmt.Errorf("%w at %d", ErrInvalidUTF8, r))
+				break
+			}
 			r += size
-			w += utf8.EncodeRune(b[w:], rr)
+			w += copy(b[w:], s[r-size:r])
+		}
+		if err != nil {
+			return "", err
 		}
 	}
-	return string(b[0:w])
+	return string(b[0:w]), nil
 }
 
 // getu4 decodes \uXXXX from the beginning of s, returning the hex value,
@@ -1,22 +1,22 @@
 package jsonport
 
-import (
-	"errors"
-	"fmt"
-)
-
-func jsonskipObject(b []byte) (int, error) {
+func (p *parser) jsonskipObject(b []byte) (int, error) {
 	if len(b) == 0 {
-		return 0, errors.New("OBJECT: expect '{' found EOF")
+		return 0, p.error(b[len(b):], ErrObjectEOF)
 	}
 	if b[0] != '{' {
-		return 0, fmt.Errorf("OBJECT: expect '{' found '%c", b[0])
+		return 0, p.errorf(b, "OBJECT: expect '{' found '%c'", b[0])
 	}
 	if len(b) < 2 {
-		return 1, errors.New("OBJECT: expect '}' found EOF")
+		return 1, p.error(b[len(b):], ErrObjectEOF)
+	}
+	err := p.enter(b)
+	defer p.leave()
+	if err != nil {
+		return 0, err
 	}
-	if b[1] == '}' {
-		return 2, nil
+	if n := 1 + p.skipspace(b[1:]); n < len(b) && b[n] == '}' {
+		return n + 1, nil
 	}
 
 	const (
@@ -27,24 +27,38 @@ func jsonskipObject(b []byte) (int, error) {
 	)
 	state := stateMemberName
 
+	var k []byte
+	members := 0
+	var seen keyset
+
 	i := 1 // skip {
 	for i < len(b) {
-		if isspace(b[i]) {
+		if p.isspace(b[i]) {
 			i++
 			continue
 		}
+		if n := p.comment(b[i:]); n > 0 {
+			i += n
+			continue
+		}
 		if state == stateMemberName {
-			_, ii, err := parseString(b[i:])
+			s, ii, err := p.parseKey(b[i:])
 			if err != nil {
-				return i, fmt.Errorf("OBJECT member.name: %s", err)
+				return i, err
+			}
+			if p.opt.Duplicates == DuplicateReject {
+				if _, err := p.duplicate(&seen, b[i:], s); err != nil {
+					return i, err
+				}
 			}
 			i += ii
+			k = s
 			state = stateColon
 			continue
 		}
 		if state == stateColon {
 			if b[i] != ':' {
-				return i, fmt.Errorf("OBJECT: expect ':' found '%c'", b[i])
+				return i, p.errorf(b[i:], "OBJECT: expect ':' found '%c'", b[i])
 			}
 			i++
 			state = stateMemberValue
@@ -52,9 +66,13 @@ func jsonskipObject(b []byte) (int, error) {
 		}
 
 		if state == stateMemberValue {
-			ii, err := jsonskip(b[i:])
+			if err := p.member(b[i:], members); err != nil {
+				return i, err
+			}
+			members++
+			ii, err := p.jsonskip(b[i:])
 			if err != nil {
-				return i, fmt.Errorf("OBJECT member.value: %s", err)
+				return i, within(err, k)
 			}
 			i += ii
 			state = stateDone
@@ -64,6 +82,9 @@ func jsonskipObject(b []byte) (int, error) {
 		if state == stateDone {
 			if b[i] == ',' {
 				i++
+				if p.trailingComma(b[i:], '}') {
+					continue // the end is handled in stateDone
+				}
 				state = stateMemberName
 				continue
 			}
@@ -71,24 +92,29 @@ func jsonskipObject(b []byte) (int, error) {
 				i++
 				return i, nil
 			}
-			return i, fmt.Errorf("OBJECT: expect ',' or '}' found '%c'", b[i])
+			return i, p.errorf(b[i:], "OBJECT: expect ',' or '}' found '%c'", b[i])
 		}
 	}
-	return i, errObjectEOF
+	return i, p.error(b[len(b):], ErrObjectEOF)
 }
 
-func jsonskipArray(b []byte) (int, error) {
+func (p *parser) jsonskipArray(b []byte) (int, error) {
 	if len(b) == 0 {
-		return 0, errors.New("ARRAY: expect '[' found EOF")
+		return 0, p.error(b[len(b):], ErrArrayEOF)
 	}
 	if b[0] != '[' {
-		return 0, fmt.Errorf("ARRAY: expect '[' found '%c'", b[0])
+		return 0, p.errorf(b, "ARRAY: expect '[' found '%c'", b[0])
 	}
 	if len(b) < 2 {
-		return 1, errors.New("ARRAY: expect ']' found EOF")
+		return 1, p.error(b[len(b):], ErrArrayEOF)
+	}
+	err := p.enter(b)
+	defer p.leave()
+	if err != nil {
+		return 0, err
 	}
-	if b[1] == ']' {
-		return 2, nil
+	if n := 1 + p.skipspace(b[1:]); n < len(b) && b[n] == ']' {
+		return n + 1, nil
 	}
 
 	const (
@@ -100,14 +126,21 @@ func jsonskipArray(b []byte) (int, error) {
 	pos := 0
 	i := 1 // skip [
 	for i < len(b) {
-		if isspace(b[i]) {
+		if p.isspace(b[i]) {
 			i++
 			continue
 		}
+		if n := p.comment(b[i:]); n > 0 {
+			i += n
+			continue
+		}
 		if state == stateValue {
-			ii, err := jsonskip(b[i:])
+			if err := p.element(b[i:], pos); err != nil {
+				return i, err
+			}
+			ii, err := p.jsonskip(b[i:])
 			if err != nil {
-				return i, fmt.Errorf("ARRAY: index: %d err: %s", pos, err)
+				return i, within(err, pos)
 			}
 			pos += 1
 			i += ii
@@ -118,6 +151,9 @@ func jsonskipArray(b []byte) (int, error) {
 		if state == stateDone {
 			if b[i] == ',' {
 				i++
+				if p.trailingComma(b[i:], ']') {
+					continue // the end is handled in stateDone
+				}
 				state = stateValue
 				continue
 			}
@@ -125,53 +161,63 @@ func jsonskipArray(b []byte) (int, error) {
 				i++
 				return i, nil
 			}
-			return i, fmt.Errorf("ARRAY: expect ',' or ']' found '%c'", b[i])
+			return i, p.errorf(b[i:], "ARRAY: expect ',' or ']' found '%c'", b[i])
 		}
 	}
-	return i, errors.New("ARRAY")
+	return i, p.error(b[len(b):], ErrArrayEOF)
 }
 
-func skipString(b []byte) (int, error) {
-	if b[0] != '"' {
-		return 0, fmt.Errorf("STRING: expect '\"' found '%c'", b[0])
+func (p *parser) skipString(b []byte) (int, error) {
+	if p.opt.Strict {
+		_, i, err := p.parseString(b)
+		return i, err
+	}
+	if p.first(b) != '"' {
+		return 0, p.errorf(b, "STRING: expect '\"' found '%c'", b[0])
 	}
+	q := b[0]
 	var i int
 	var escaped bool
 	for i, c := range b[1:] {
 		if c == '\\' {
 			escaped = !escaped
-		} else if c == '"' && !escaped {
+		} else if c == q && !escaped {
+			if err := p.limit(b, "MaxStringLen", p.opt.MaxStringLen, i); err != nil {
+				return i + 2, err
+			}
 			return i + 2, nil
 		} else {
 			escaped = false
 		}
 	}
-	return i, errStringEOF
+	return i, p.error(b[len(b):], ErrStringEOF)
 }
 
-func jsonskip(b []byte) (int, error) {
-	i := skipspace(b)
+func (p *parser) jsonskip(b []byte) (int, error) {
+	i := p.skipspace(b)
 	b = b[i:]
 	if len(b) == 0 {
-		return 0, errJSONEOF
+		return 0, p.error(b[len(b):], ErrJSONEOF)
 	}
-	switch b[0] {
+	switch p.first(b) {
 	case '{': // skip to unquoted '}'
-		return jsonskipObject(b)
+		ii, err := p.jsonskipObject(b)
+		return i + ii, err
 	case '[':
-		return jsonskipArray(b)
+		ii, err := p.jsonskipArray(b)
+		return i + ii, err
 
 	case '"':
-		ii, err := skipString(b)
+		ii, err := p.skipString(b)
 		return i + ii, err
 	case 't', 'f':
-		_, ii, err := parseBool(b)
+		_, ii, err := p.parseBool(b)
 		return i + ii, err
 	case 'n':
-		ii, err := parseNull(b)
+		ii, err := p.parseNull(b)
 		return i + ii, err
 	default:
-		_, ii, err := parseNumber(b)
+		_, ii, err := p.parseNumber(b)
 		return i + ii, err
 	}
 }
@@ -0,0 +1,45 @@
+package jsonport
+
+import "fmt"
+
+// A StringPolicy tells how strings which can not be decoded are handled by String,
+// Keys, Member and the like: strings with invalid escapes like \q or \u12,
+// lone surrogates like \ud800, raw control characters or invalid UTF-8.
+type StringPolicy uint8
+
+const (
+	// StringReject fails with an error wrapping ErrInvalidString,
+	// or ErrInvalidUTF8 for invalid UTF-8. It is the default.
+	StringReject StringPolicy = iota
+	// StringReplace replaces each of them with U+FFFD.
+	StringReplace
+	// StringRaw passes the bytes of them through as they are in the input.
+	StringRaw
+)
+
+// InvalidStrings sets how strings of j and the values got from it are decoded
+// if they are invalid.
+func (j *Json) InvalidStrings(sp StringPolicy) {
+	j.sp = sp
+}
+
+// name returns the member name of e decoded by sp.
+// For an invalid name with StringReject, the name decoded by StringReplace
+// is returned with the error, so that lookups fail only if it matches.
+func (e *kv) name(sp StringPolicy, json5 bool) (string, error) {
+	k := e.key(json5)
+	if !e.bad || sp == StringReplace {
+		return k, nil
+	}
+	raw, err := sp.unquote(e.k, json5)
+	if err != nil {
+		return k, fmt.Errorf("member name %q: %w", k, err)
+	}
+	return raw, nil
+}
+
+// name decodes the member name s in the input like kv.name.
+func (p *parser) name(s []byte) (string, error) {
+	e := kv{k: s}
+	return e.name(p.opt.InvalidStrings, p.opt.JSON5)
+}
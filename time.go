@@ -0,0 +1,147 @@
+package jsonport
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+var nsPerSecond = big.NewInt(int64(time.Second))
+
+// Time converts current json value of STRING to time.Time by time.Parse with layout,
+// time.RFC3339 is used if layout is empty, it accepts fractional seconds as well.
+// The zero time is returned for NULL with NullToZero.
+func (j Json) Time(layout string) (time.Time, error) {
+	if j.zero() {
+		return time.Time{}, nil
+	}
+	if j.tp != STRING {
+		return time.Time{}, j.mismatch(STRING)
+	}
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	s, err := j.sp.unquote(j.str(), j.json5)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(layout, s)
+}
+
+// UnixTime converts current json value of NUMBER to time.Time,
+// the number is the time elapsed since January 1, 1970 UTC in unit,
+// like time.Second or time.Millisecond. Fractions like 1438194274.5 are kept
+// to the nanosecond. STRING like "1438194274" is converted with StringToNumber.
+// The time returned is in the local time zone as time.Unix does.
+func (j Json) UnixTime(unit time.Duration) (time.Time, error) {
+	n, err := j.number()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if i, err := n.Int64(); err == nil && unit > 0 {
+		if unit%time.Second == 0 {
+			s := int64(unit / time.Second)
+			if i <= math.MaxInt64/s && i >= math.MinInt64/s {
+				return time.Unix(i*s, 0), nil
+			}
+		} else if time.Second%unit == 0 {
+			d := int64(time.Second / unit)
+			return time.Unix(i/d, i%d*int64(unit)), nil
+		}
+	}
+	ns, err := nanoseconds(n, unit, "time.Time")
+	if err != nil {
+		return time.Time{}, err
+	}
+	sec, nsec := new(big.Int).DivMod(ns, nsPerSecond, new(big.Int))
+	if !sec.IsInt64() {
+		return time.Time{}, &RangeError{Num: n, Type: "time.Time"}
+	}
+	return time.Unix(sec.Int64(), nsec.Int64()), nil
+}
+
+// Duration converts current json value to time.Duration.
+// STRING is parsed by time.ParseDuration, like "1h30m" or "1.5s",
+// NUMBER is the number of seconds, like 90 or 1.5.
+// With StringToNumber, STRING of seconds like "90" is converted as well.
+func (j Json) Duration() (time.Duration, error) {
+	if j.tp == STRING && !j.null() {
+		s, err := j.sp.unquote(j.str(), j.json5)
+		if err != nil {
+			return 0, err
+		}
+		d, err := time.ParseDuration(s)
+		if err == nil || j.co&StringToNumber == 0 {
+			return d, err
+		}
+	}
+	n, err := j.number()
+	if err != nil {
+		return 0, err
+	}
+	ns, err := nanoseconds(n, time.Second, "time.Duration")
+	if err != nil {
+		return 0, err
+	}
+	if !ns.IsInt64() {
+		return 0, &RangeError{Num: n, Type: "time.Duration"}
+	}
+	return time.Duration(ns.Int64()), nil
+}
+
+// nanoseconds returns n in unit as nanoseconds, rounded toward negative infinity.
+func nanoseconds(n Number, unit time.Duration, t string) (*big.Int, error) {
+	r, err := n.Rat()
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return nil, &RangeError{Num: n, Type: t}
+		}
+		return nil, n.syntaxError(t)
+	}
+	r.Mul(r, new(big.Rat).SetInt64(int64(unit)))
+	return new(big.Int).Div(r.Num(), r.Denom()), nil // Euclidean, Denom is positive
+}
+
+// GetTime converts json value specified by keys to time.Time,
+// it is equal to Get(keys...).Time(layout)
+func (j Json) GetTime(layout string, keys ...interface{}) (time.Time, error) {
+	v := j.Get(keys...)
+	ret, err := v.Time(layout)
+	return ret, v.at(err, keys)
+}
+
+// GetUnixTime converts json value specified by keys to time.Time,
+// it is equal to Get(keys...).UnixTime(unit)
+func (j Json) GetUnixTime(unit time.Duration, keys ...interface{}) (time.Time, error) {
+	v := j.Get(keys...)
+	ret, err := v.UnixTime(unit)
+	return ret, v.at(err, keys)
+}
+
+// GetDuration converts json value specified by keys to time.Duration,
+// it is equal to Get(keys...).Duration()
+func (j Json) GetDuration(keys ...interface{}) (time.Duration, error) {
+	v := j.Get(keys...)
+	ret, err := v.Duration()
+	return ret, v.at(err, keys)
+}
+
+// TimeArray converts current json value to []time.Time, see Time.
+// error is returned if any element can not be parsed with layout.
+func (j Json) TimeArray(layout string) ([]time.Time, error) {
+	arr, err := j.Array()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]time.Time, len(arr))
+	for i, e := range arr {
+		t, err := j.returnj(e).Time(layout)
+		if err != nil {
+			return nil, err
+		}
+		ret[i] = t
+	}
+	return ret, nil
+}
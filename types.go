@@ -1,7 +1,10 @@
 package jsonport
 
 import (
+	"errors"
+	"math/big"
 	"strconv"
+	"strings"
 )
 
 type Type int
@@ -44,10 +47,139 @@ func (n Number) String() string { return string(n) }
 
 // Float64 returns the number as a float64.
 func (n Number) Float64() (float64, error) {
+	if n.hex() {
+		return strconv.ParseFloat(string(n)+"p0", 64)
+	}
 	return strconv.ParseFloat(string(n), 64)
 }
 
 // Int64 returns the number as an int64.
 func (n Number) Int64() (int64, error) {
+	if n.hex() {
+		return strconv.ParseInt(string(n), 0, 64)
+	}
 	return strconv.ParseInt(string(n), 10, 64)
 }
+
+// Uint64 returns the number as a uint64.
+func (n Number) Uint64() (uint64, error) {
+	if n.hex() {
+		return strconv.ParseUint(string(n), 0, 64)
+	}
+	return strconv.ParseUint(string(n), 10, 64)
+}
+
+// BigInt returns the number as a *big.Int without losing precision.
+// Numbers like 1.0 and 1e3 are accepted, ErrNotInteger is returned for 1.5.
+func (n Number) BigInt() (*big.Int, error) {
+	if n.hex() {
+		if i, ok := new(big.Int).SetString(string(n), 0); ok {
+			return i, nil
+		}
+		return nil, n.syntaxError("BigInt")
+	}
+	if i, ok := new(big.Int).SetString(string(n), 10); ok {
+		return i, nil
+	}
+	r, err := n.Rat()
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return nil, &RangeError{Num: n, Type: "big.Int"}
+		}
+		return nil, n.syntaxError("BigInt")
+	}
+	if !r.IsInt() {
+		return nil, &strconv.NumError{Func: "BigInt", Num: string(n), Err: ErrNotInteger}
+	}
+	return r.Num(), nil
+}
+
+// BigFloat returns the number as a *big.Float,
+// with the precision enough for all the digits of the number.
+// Infinity of JSON5 is accepted, NaN is not.
+func (n Number) BigFloat() (*big.Float, error) {
+	s := strings.TrimLeft(string(n), "+-")
+	if s == "Infinity" {
+		return new(big.Float).SetInf(s != string(n) && n[0] == '-'), nil
+	}
+	base := 10
+	if n.hex() {
+		base = 0
+	}
+	prec := uint(len(n))*4 + 64
+	f, _, err := big.ParseFloat(string(n), base, prec, big.ToNearestEven)
+	if err != nil {
+		return nil, n.syntaxError("BigFloat")
+	}
+	return f, nil
+}
+
+// Rat returns the exact value of the number as a *big.Rat,
+// like 3/2 for 1.5 and 1/100 for 1e-2.
+// A *RangeError is returned for an exponent too large for big.Rat, like 1e100000000.
+func (n Number) Rat() (*big.Rat, error) {
+	s := string(n)
+	if len(s) > 0 && s[0] == '+' {
+		s = s[1:]
+	}
+	if strings.IndexByte(s, '/') < 0 {
+		if r, ok := new(big.Rat).SetString(s); ok {
+			return r, nil
+		}
+		if isNumber([]byte(s)) {
+			return nil, &RangeError{Num: n, Type: "big.Rat"}
+		}
+	}
+	return nil, n.syntaxError("Rat")
+}
+
+// IsInteger reports whether the number is an integer, like 1, 1.0, 1e3 or 0x1F.
+// It is false for malformed numbers, Infinity and NaN.
+func (n Number) IsInteger() bool {
+	if n.hex() {
+		return true
+	}
+	s := strings.TrimLeft(string(n), "+-")
+	exp := int64(0)
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		var err error
+		exp, err = strconv.ParseInt(s[i+1:], 10, 32)
+		if err != nil && !errors.Is(err, strconv.ErrRange) {
+			return false
+		}
+		s = s[:i]
+	}
+	frac := ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		s, frac = s[:i], s[i+1:]
+	}
+	if len(s)+len(frac) == 0 || !isDigits(s) || !isDigits(frac) {
+		return false
+	}
+	// the value is digits * 10^(exp - len(frac)), trailing zeros of digits raise the exponent.
+	digits := s + frac
+	d := strings.TrimRight(digits, "0")
+	if d == "" {
+		return true
+	}
+	return exp-int64(len(frac))+int64(len(digits)-len(d)) >= 0
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (n Number) syntaxError(fn string) error {
+	return &strconv.NumError{Func: fn, Num: string(n), Err: strconv.ErrSyntax}
+}
+
+// hex reports whether n is a hexadecimal number of JSON5 like 0x1F.
+func (n Number) hex() bool {
+	s := strings.TrimLeft(string(n), "+-")
+	return len(s) > 1 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X')
+}